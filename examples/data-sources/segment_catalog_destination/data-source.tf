@@ -0,0 +1,12 @@
+data "segment_catalog_destination" "amplitude" {
+  name = "amplitude"
+}
+
+# Settings of the destination, e.g. to check the keys of the `config` of `segment_destination`
+output "amplitude_settings" {
+  value = { for s in data.segment_catalog_destination.amplitude.settings : s.name => s.type }
+}
+
+output "amplitude_supports_cloud_mode" {
+  value = contains(data.segment_catalog_destination.amplitude.connection_modes, "CLOUD")
+}
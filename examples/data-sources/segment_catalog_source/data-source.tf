@@ -0,0 +1,9 @@
+data "segment_catalog_source" "javascript" {
+  name = "javascript"
+}
+
+# Typos in the catalog name fail while planning
+resource "segment_source" "website" {
+  source_name  = "website"
+  catalog_name = data.segment_catalog_source.javascript.catalog_name
+}
@@ -0,0 +1,16 @@
+# Source managed outside of Terraform, e.g. by the mobile team
+data "segment_source" "ios" {
+  source_name = "ios-app"
+}
+
+# Filter of a destination of the source
+resource "segment_destination_filter" "ios_drop_debug_events" {
+  destination = "${data.segment_source.ios.source_name}/amplitude"
+  title       = "Drop debug events"
+  description = "Debug builds must not send events to Amplitude"
+  condition   = "context.app.build = \"debug\""
+  enabled     = true
+  actions {
+    drop {}
+  }
+}
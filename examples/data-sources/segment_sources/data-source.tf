@@ -0,0 +1,9 @@
+# Sources of the mobile apps connected to a tracking plan
+data "segment_sources" "mobile" {
+  name_prefix   = "mobile-"
+  tracking_plan = "rs_123abc" # Segment ID of the tracking plan
+}
+
+output "mobile_sources" {
+  value = [for s in data.segment_sources.mobile.sources : s.source_name]
+}
@@ -1,7 +1,26 @@
 provider "segment" {
   access_token = "test_access_token"       # or via SEGMENT_ACCESS_TOKEN env var.
   workspace    = "test_workspace"          # or via SEGMENT_WORKSPACE env var.
+  # Alternatively, the token can be read from a file or printed by a command
+  # access_token_file    = "/run/secrets/segment-token"             # or via SEGMENT_ACCESS_TOKEN_FILE env var.
+  # access_token_command = "vault read -field=token secret/segment" # or via SEGMENT_ACCESS_TOKEN_COMMAND env var.
   unsupported_destination_config_props = [ # Optional
     "appboy/datacenter"
   ]
+
+  # Optional transport settings
+  endpoint        = "https://platform.segmentapis.com" # or via SEGMENT_ENDPOINT env var.
+  http_proxy      = "http://proxy.internal:3128"       # or via SEGMENT_HTTP_PROXY env var.
+  ca_cert_file    = "/etc/ssl/certs/corporate-ca.pem"  # or via SEGMENT_CA_CERT_FILE env var.
+  request_timeout = "30s"                              # or via SEGMENT_REQUEST_TIMEOUT env var.
+  debug_http      = false                              # or via SEGMENT_DEBUG_HTTP env var.
+
+  # Optional retry settings, for requests failing with a 429, or a 5xx or a network error when reading or deleting
+  max_retries         = 10     # or via SEGMENT_MAX_RETRIES env var.
+  retry_initial_delay = "75ms" # or via SEGMENT_RETRY_INITIAL_DELAY env var.
+  retry_max_delay     = "30s"  # or via SEGMENT_RETRY_MAX_DELAY env var.
+
+  # Optional client-side rate limit, shared by all resources
+  requests_per_second = 10 # or via SEGMENT_REQUESTS_PER_SECOND env var.
+  burst               = 5  # or via SEGMENT_BURST env var.
 }
@@ -0,0 +1,5 @@
+# Import with the ID of the destination, in the default workspace of the provider
+terraform import segment_destination.example my-source/google-analytics
+
+# Import from another workspace
+terraform import segment_destination.example my-workspace/my-source/google-analytics
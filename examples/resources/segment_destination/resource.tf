@@ -39,3 +39,20 @@ resource "segment_destination" "test__amazon-kinesis" {
     )
   }
 }
+
+# A destination with settings only editable in the Segment UI, which is disabled rather than deleted on destroy
+resource "segment_destination" "test__google-analytics" {
+  source     = "simple_test"
+  name       = "google-analytics"
+  enabled    = true
+  on_destroy = "disable"
+
+  config = {
+    "trackingId" = jsonencode(
+      {
+        type  = "string"
+        value = "UA-123456-1"
+      }
+    )
+  }
+}
@@ -0,0 +1,5 @@
+# Import with the ID of the destination filter, in the default workspace of the provider
+terraform import segment_destination_filter.example my-source/google-analytics/df_123
+
+# Import from another workspace
+terraform import segment_destination_filter.example my-workspace/my-source/google-analytics/df_123
@@ -0,0 +1,5 @@
+# Import with the ID of the source, in the default workspace of the provider
+terraform import segment_source.example my-source
+
+# Import from another workspace
+terraform import segment_source.example my-workspace/my-source
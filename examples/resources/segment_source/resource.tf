@@ -34,3 +34,17 @@ resource "segment_source" "test_with_custom_config" {
     forward_blocked_events_to              = "segment_blocks"     # Source needs to be defined first
   }
 }
+
+# Production source, which Terraform refuses to destroy or replace
+resource "segment_source" "production" {
+  catalog_name = "catalog/sources/javascript"
+  source_name  = "production"
+
+  deletion_protection = true
+}
+
+# Write key of a source, e.g. to store it in a secrets manager
+output "simple_test_write_key" {
+  value     = segment_source.simple_test.write_keys[0]
+  sensitive = true
+}
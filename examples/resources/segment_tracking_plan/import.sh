@@ -0,0 +1,5 @@
+# Import with the ID of the tracking plan, in the default workspace of the provider
+terraform import segment_tracking_plan.example rs_123
+
+# Import from another workspace
+terraform import segment_tracking_plan.example my-workspace/rs_123
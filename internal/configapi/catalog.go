@@ -0,0 +1,101 @@
+package configapi
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Prefixes of the catalog names of sources and destinations
+const (
+	CatalogSourcePrefix      = "catalog/sources/"
+	CatalogDestinationPrefix = "catalog/destinations/"
+)
+
+// CatalogEntry is a type of source or destination in the Segment catalog
+type CatalogEntry struct {
+	// Name is the catalog name of the entry, e.g. `catalog/sources/javascript`
+	Name            string            `json:"name"`
+	DisplayName     string            `json:"display_name"`
+	Description     string            `json:"description"`
+	Categories      CatalogCategories `json:"categories"`
+	ConnectionModes ConnectionModes   `json:"connection_modes"`
+	Settings        []CatalogSetting  `json:"settings"`
+}
+
+// CatalogCategories are the categories of a catalog entry, e.g. `Analytics`. Unset categories are empty.
+type CatalogCategories struct {
+	Primary    string   `json:"primary"`
+	Secondary  string   `json:"secondary"`
+	Additional []string `json:"additional"`
+}
+
+// All returns the categories which are set, the primary one first
+func (c CatalogCategories) All() []string {
+	all := []string{}
+	for _, category := range append([]string{c.Primary, c.Secondary}, c.Additional...) {
+		if category != "" {
+			all = append(all, category)
+		}
+	}
+
+	return all
+}
+
+// ConnectionModes are the platforms events can be sent from to a catalog entry, for each connection mode. The API sends
+// them as an object of modes, `device` and `cloud`, to objects of platforms, `web`, `mobile` and `server`, to booleans.
+type ConnectionModes struct {
+	Device ConnectionModePlatforms `json:"device"`
+	Cloud  ConnectionModePlatforms `json:"cloud"`
+}
+
+// ConnectionModePlatforms are the platforms a connection mode supports
+type ConnectionModePlatforms struct {
+	Web    bool `json:"web"`
+	Mobile bool `json:"mobile"`
+	Server bool `json:"server"`
+}
+
+// Supported returns the modes supported on at least one platform, as the `connection_mode` of destinations, sorted
+func (m ConnectionModes) Supported() []string {
+	supported := []string{}
+	if m.Cloud.any() {
+		supported = append(supported, "CLOUD")
+	}
+	if m.Device.any() {
+		supported = append(supported, "DEVICE")
+	}
+
+	return supported
+}
+
+func (p ConnectionModePlatforms) any() bool {
+	return p.Web || p.Mobile || p.Server
+}
+
+// CatalogSetting is a setting of the sources or destinations of a catalog entry
+type CatalogSetting struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Description string `json:"description"`
+	// Type is the type of the value of the setting, e.g. `string`, `boolean` or `map`
+	Type     string `json:"type"`
+	Required bool   `json:"required"`
+	// Default is the JSON value the setting defaults to, if any
+	Default json.RawMessage `json:"default,omitempty"`
+}
+
+// GetCatalogSource returns a type of source of the catalog, e.g. `javascript`
+func (c *Client) GetCatalogSource(ctx context.Context, srcName string) (CatalogEntry, error) {
+	var e CatalogEntry
+	err := c.getJSON(ctx, CatalogSourcePrefix+srcName, &e)
+
+	return e, err
+}
+
+// GetCatalogDestination returns a type of destination of the catalog, e.g. `amplitude`
+func (c *Client) GetCatalogDestination(ctx context.Context, destName string) (CatalogEntry, error) {
+	var e CatalogEntry
+	err := c.getJSON(ctx, CatalogDestinationPrefix+destName, &e)
+
+	return e, err
+}
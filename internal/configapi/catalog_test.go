@@ -0,0 +1,52 @@
+package configapi_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uswitch/terraform-provider-segment/internal/configapi"
+)
+
+// getCatalogDestinationResponse is a v1beta catalog destination, with its categories as primary, secondary and
+// additional ones, and its connection modes as the platforms supported by each mode. It isn't a recorded response.
+const getCatalogDestinationResponse = `{
+	"name": "catalog/destinations/amplitude",
+	"display_name": "Amplitude",
+	"description": "Product analytics",
+	"categories": {
+		"primary": "Analytics",
+		"secondary": "",
+		"additional": ["Attribution"]
+	},
+	"connection_modes": {
+		"device": {"web": true, "mobile": true, "server": false},
+		"cloud": {"web": false, "mobile": false, "server": false}
+	},
+	"settings": [
+		{"name": "apiKey", "display_name": "API Key", "type": "string", "required": true},
+		{"name": "trackAllPages", "display_name": "Track All Pages", "type": "boolean", "default": false}
+	]
+}`
+
+func TestClient_GetCatalogDestination(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1beta/catalog/destinations/amplitude", r.URL.Path)
+		w.Write([]byte(getCatalogDestinationResponse))
+	}))
+	defer server.Close()
+
+	client := configapi.NewClient("token", "myworkspace", configapi.WithBaseURL(server.URL+"/"))
+	entry, err := client.GetCatalogDestination(context.Background(), "amplitude")
+
+	require.NoError(t, err)
+	assert.Equal(t, "Amplitude", entry.DisplayName)
+	assert.Equal(t, []string{"Analytics", "Attribution"}, entry.Categories.All())
+	assert.Equal(t, configapi.ConnectionModePlatforms{Web: true, Mobile: true}, entry.ConnectionModes.Device)
+	assert.Equal(t, []string{"DEVICE"}, entry.ConnectionModes.Supported(), "cloud mode isn't supported on any platform")
+	require.Len(t, entry.Settings, 2)
+	assert.JSONEq(t, "false", string(entry.Settings[1].Default))
+}
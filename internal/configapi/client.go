@@ -0,0 +1,293 @@
+// Package configapi is a client for the Segment Config API.
+//
+// It mirrors the client from github.com/uswitch/segment-config-go and reuses its types, but gives the provider
+// control over the API endpoint and the HTTP transport used to reach it.
+//
+// TODO: segment-config-go hardcodes its base URL and uses http.DefaultClient. Add WithBaseURL and WithHTTPClient
+// options to it, bump it and drop the methods duplicated here, keeping only the provider-specific layers (retries,
+// rate limiting, token sources and tracing). New endpoints belong upstream rather than in this package; the ones
+// added here ahead of it have to move there first: ListSources following page tokens, and GetCatalogSource and
+// GetCatalogDestination.
+package configapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/uswitch/segment-config-go/segment"
+	"github.com/uswitch/terraform-provider-segment/internal/tracing"
+	"github.com/uswitch/terraform-provider-segment/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// DefaultBaseURL is the production Segment Config API endpoint
+	DefaultBaseURL = "https://platform.segmentapis.com"
+	apiVersion     = "v1beta"
+	mediaType      = "application/json"
+)
+
+// tracer creates a span per request, with a child span per attempt
+var tracer = tracing.Tracer("configapi")
+
+// Client manages communication with the Segment Config API
+type Client struct {
+	baseURL    string
+	apiVersion string
+	tokens     TokenSource
+	workspace  string
+	client     *http.Client
+	retry      utils.RetryPolicy
+	limiter    *RateLimiter
+}
+
+// Option customises a Client
+type Option func(*Client)
+
+// WithBaseURL points the client at a different Config API endpoint, e.g. a local mock
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to perform requests
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.client = client
+	}
+}
+
+// WithRetryPolicy retries requests failing with a 429 according to the policy. Idempotent requests are also retried on a
+// 5xx or a network error, which may happen after Segment applied a create or an update. Requests are not retried by
+// default.
+func WithRetryPolicy(policy utils.RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// NewClient creates a new Segment Config API client
+func NewClient(accessToken string, workspace string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    DefaultBaseURL,
+		apiVersion: apiVersion,
+		tokens:     StaticToken(accessToken),
+		workspace:  workspace,
+		client:     http.DefaultClient,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Workspace returns the slug of the workspace the client operates on
+func (c *Client) Workspace() string {
+	return c.workspace
+}
+
+// doRequest performs a request, retrying it according to the retry policy of the client, and once more with a new
+// token if the token source can replace a rejected one
+func (c *Client) doRequest(ctx context.Context, method, endpoint string, data interface{}) (body []byte, err error) {
+	ctx, span := tracer.Start(ctx, "Config API "+method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		semconv.HTTPMethodKey.String(method),
+		attribute.String("segment.endpoint", endpoint),
+	))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	// Encode data if we are passed an object.
+	var payload []byte
+	if data != nil {
+		b := bytes.NewBuffer(nil)
+		if err := json.NewEncoder(b).Encode(data); err != nil {
+			return nil, fmt.Errorf("json encoding data for doRequest failed: %w", err)
+		}
+		payload = b.Bytes()
+	}
+
+	body, token, err := c.doRequestWithToken(ctx, method, endpoint, payload)
+
+	var apiErr *segment.SegmentApiError
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusUnauthorized && c.tokens.Invalidate(token) {
+		tflog.SubsystemInfo(ctx, utils.LogSegmentAPI, "The access token was rejected, retrying with a new one", map[string]interface{}{
+			"method":   method,
+			"endpoint": endpoint,
+		})
+		body, _, err = c.doRequestWithToken(ctx, method, endpoint, payload)
+	}
+
+	return body, err
+}
+
+// doRequestWithToken performs a request with the current token, retrying it according to the retry policy. It returns
+// the token used, so it can be invalidated.
+func (c *Client) doRequestWithToken(ctx context.Context, method, endpoint string, payload []byte) ([]byte, string, error) {
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var body []byte
+	attempt := 0
+	err = utils.Retry(ctx, c.retry, func() error {
+		attempt++
+		ctx, span := tracer.Start(ctx, "Config API attempt", trace.WithAttributes(attribute.Int("segment.attempt", attempt)))
+
+		var err error
+		body, err = c.doRequestOnce(ctx, method, endpoint, token, payload)
+
+		var retryable *utils.RetryableError
+		span.SetAttributes(attribute.Bool("segment.retryable", errors.As(err, &retryable)))
+		tracing.EndSpan(span, err)
+
+		return err
+	})
+
+	return body, token, err
+}
+
+// doRequestOnce performs a single request. Transient failures are returned as *utils.RetryableError.
+func (c *Client) doRequestOnce(ctx context.Context, method, endpoint string, token string, payload []byte) ([]byte, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("waiting for the rate limiter failed: %w", err)
+	}
+
+	uri := fmt.Sprintf("%s/%s/%s", c.baseURL, c.apiVersion, strings.Trim(endpoint, "/"))
+	req, err := http.NewRequestWithContext(ctx, method, uri, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("creating %s request to %s failed: %w", method, uri, err)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	req.Header.Set("Content-Type", mediaType)
+
+	resp, err := c.client.Do(req)
+	if err == nil {
+		trace.SpanFromContext(ctx).SetAttributes(semconv.HTTPStatusCodeKey.Int(resp.StatusCode))
+	}
+	if err != nil {
+		err = fmt.Errorf("performing %s request to %s failed: %w", method, uri, err)
+		if ctx.Err() != nil || !isIdempotent(method) {
+			return nil, err
+		}
+		return nil, &utils.RetryableError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	// Segment rejects rate limited requests before processing them, so they can be retried whatever their method
+	if resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode >= http.StatusInternalServerError && isIdempotent(method)) {
+		return nil, &utils.RetryableError{Err: responseError(resp, endpoint, uri), RetryAfter: retryAfter(resp)}
+	}
+	if err := responseError(resp, endpoint, uri); err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("decoding response from %s request to %s failed: %w", method, uri, err)
+	}
+
+	return body, nil
+}
+
+// isIdempotent returns whether a request can be repeated without further effect, so it is safe to retry after a failure
+// which gives no guarantee the request wasn't processed
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// responseError maps the status code of a response to a *segment.SegmentApiError, or nil for successful responses
+func responseError(resp *http.Response, endpoint string, uri string) error {
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+		return nil
+	case http.StatusUnauthorized:
+		return &segment.SegmentApiError{Message: "invalid access token", Code: resp.StatusCode}
+	case http.StatusForbidden:
+		return &segment.SegmentApiError{Message: fmt.Sprintf("unauthorized access to endpoint: %s", endpoint), Code: resp.StatusCode}
+	case http.StatusNotFound:
+		return &segment.SegmentApiError{Message: fmt.Sprintf("the requested uri does not exist: %s", uri), Code: resp.StatusCode}
+	case http.StatusBadRequest, http.StatusInternalServerError:
+		return handleErrorResponse(resp)
+	case http.StatusTooManyRequests:
+		return &segment.SegmentApiError{Message: "too many requests to API", Code: resp.StatusCode}
+	default:
+		return &segment.SegmentApiError{Message: "bad response code", Code: resp.StatusCode}
+	}
+}
+
+// retryAfter parses the Retry-After header of a response, either in seconds or as an HTTP date. It returns 0 when absent or invalid.
+func retryAfter(resp *http.Response) time.Duration {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+
+	return 0
+}
+
+func handleErrorResponse(resp *http.Response) error {
+	errBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("the request error body is invalid: %s", errBody)
+	}
+
+	var segmentErr segment.SegmentApiError
+	if err = json.Unmarshal(errBody, &segmentErr); err != nil {
+		return fmt.Errorf("request error unknown: %s", errBody)
+	}
+	if segmentErr.Code == 0 {
+		segmentErr.Code = resp.StatusCode
+	}
+
+	return &segmentErr
+}
+
+func (c *Client) getJSON(ctx context.Context, endpoint string, dst interface{}) error {
+	return c.sendJSON(ctx, http.MethodGet, endpoint, nil, dst)
+}
+
+// sendJSON performs a request and decodes the response body into dst, when dst is not nil
+func (c *Client) sendJSON(ctx context.Context, method string, endpoint string, body interface{}, dst interface{}) error {
+	data, err := c.doRequest(ctx, method, endpoint, body)
+	if err != nil {
+		return err
+	}
+
+	if dst == nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(data, dst); err != nil {
+		return fmt.Errorf("failed to unmarshal %s response: %w", endpoint, err)
+	}
+
+	return nil
+}
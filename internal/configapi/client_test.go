@@ -0,0 +1,245 @@
+package configapi_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uswitch/segment-config-go/segment"
+	"github.com/uswitch/terraform-provider-segment/internal/configapi"
+	"github.com/uswitch/terraform-provider-segment/internal/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestClient_UsesBaseURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1beta/workspaces/myworkspace/sources/mysource", r.URL.Path)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"name": "workspaces/myworkspace/sources/mysource", "catalog_name": "catalog/sources/javascript"}`))
+	}))
+	defer server.Close()
+
+	client := configapi.NewClient("token", "myworkspace", configapi.WithBaseURL(server.URL+"/"))
+	src, err := client.GetSource(context.Background(), "mysource")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "catalog/sources/javascript", src.CatalogName)
+}
+
+func TestClient_MapsErrors(t *testing.T) {
+	tests := map[string]struct {
+		status int
+		body   string
+		code   int
+	}{
+		"not found":         {status: http.StatusNotFound, code: http.StatusNotFound},
+		"too many requests": {status: http.StatusTooManyRequests, code: http.StatusTooManyRequests},
+		"bad request":       {status: http.StatusBadRequest, body: `{"error": "invalid config", "code": 3}`, code: 3},
+		"bad gateway":       {status: http.StatusBadGateway, code: http.StatusBadGateway},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.status)
+				w.Write([]byte(test.body))
+			}))
+			defer server.Close()
+
+			client := configapi.NewClient("token", "myworkspace", configapi.WithBaseURL(server.URL))
+			_, err := client.GetSource(context.Background(), "mysource")
+
+			var apiErr *segment.SegmentApiError
+			if assert.True(t, errors.As(err, &apiErr)) {
+				assert.Equal(t, test.code, apiErr.Code)
+			}
+		})
+	}
+}
+
+func TestNewHTTPClient_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	httpClient, err := configapi.NewHTTPClient(configapi.TransportConfig{Timeout: 20 * time.Millisecond})
+	assert.NoError(t, err)
+
+	client := configapi.NewClient("token", "myworkspace", configapi.WithBaseURL(server.URL), configapi.WithHTTPClient(httpClient))
+	_, err = client.GetWorkspace(context.Background())
+
+	assert.Error(t, err)
+}
+
+func TestNewHTTPClient_InvalidCACertFile(t *testing.T) {
+	_, err := configapi.NewHTTPClient(configapi.TransportConfig{CACertFile: "does-not-exist.pem"})
+
+	assert.Error(t, err)
+}
+
+func TestClient_RetriesTransientErrors(t *testing.T) {
+	statuses := []int{http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusOK}
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := statuses[calls]
+		calls++
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "1")
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(`{"name": "workspaces/myworkspace"}`))
+	}))
+	defer server.Close()
+
+	client := configapi.NewClient("token", "myworkspace",
+		configapi.WithBaseURL(server.URL),
+		configapi.WithRetryPolicy(utils.RetryPolicy{MaxRetries: 2, InitialDelay: time.Millisecond}),
+	)
+	start := time.Now()
+	_, err := client.GetWorkspace(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second, "Retry-After should be honoured")
+}
+
+func TestClient_DoesNotRetryClientErrors(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := configapi.NewClient("token", "myworkspace",
+		configapi.WithBaseURL(server.URL),
+		configapi.WithRetryPolicy(utils.RetryPolicy{MaxRetries: 2, InitialDelay: time.Millisecond}),
+	)
+	_, err := client.GetWorkspace(context.Background())
+
+	var apiErr *segment.SegmentApiError
+	if assert.True(t, errors.As(err, &apiErr)) {
+		assert.Equal(t, http.StatusNotFound, apiErr.Code)
+	}
+	assert.Equal(t, 1, calls)
+}
+
+func TestClient_RetriesCreatesOnlyWhenRateLimited(t *testing.T) {
+	statuses := []int{http.StatusTooManyRequests, http.StatusServiceUnavailable}
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statuses[calls])
+		calls++
+	}))
+	defer server.Close()
+
+	client := configapi.NewClient("token", "myworkspace",
+		configapi.WithBaseURL(server.URL),
+		configapi.WithRetryPolicy(utils.RetryPolicy{MaxRetries: 2, InitialDelay: time.Millisecond}),
+	)
+	_, err := client.CreateSource(context.Background(), "src", "catalog/sources/javascript")
+
+	var apiErr *segment.SegmentApiError
+	if assert.True(t, errors.As(err, &apiErr)) {
+		assert.Equal(t, http.StatusServiceUnavailable, apiErr.Code)
+	}
+	assert.Equal(t, 2, calls, "a create failing with a 5xx may have been applied, so it should not be retried")
+}
+
+var (
+	spans          = tracetest.NewInMemoryExporter()
+	setupSpansOnce sync.Once
+)
+
+// recordSpans records the spans ended by the test. The global tracer provider can only be set once for the tracers
+// created beforehand, so it is shared by the tests.
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	setupSpansOnce.Do(func() {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(spans)))
+	})
+	spans.Reset()
+	t.Cleanup(spans.Reset)
+
+	return spans
+}
+
+func spanAttribute(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+
+	return attribute.Value{}
+}
+
+func TestClient_TracesRequestsAndAttempts(t *testing.T) {
+	exporter := recordSpans(t)
+	statuses := []int{http.StatusServiceUnavailable, http.StatusOK}
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statuses[calls])
+		calls++
+		w.Write([]byte(`{"name": "workspaces/myworkspace"}`))
+	}))
+	defer server.Close()
+
+	client := configapi.NewClient("token", "myworkspace",
+		configapi.WithBaseURL(server.URL),
+		configapi.WithRetryPolicy(utils.RetryPolicy{MaxRetries: 2, InitialDelay: time.Millisecond}),
+	)
+	_, err := client.GetWorkspace(context.Background())
+	require.NoError(t, err)
+
+	ended := exporter.GetSpans()
+	require.Len(t, ended, 3)
+	first, second, request := ended[0], ended[1], ended[2]
+
+	assert.Equal(t, "Config API GET", request.Name)
+	assert.False(t, request.Parent.IsValid())
+	assert.Equal(t, "GET", spanAttribute(request, "http.method").AsString())
+	assert.Equal(t, "workspaces/myworkspace", spanAttribute(request, "segment.endpoint").AsString())
+	assert.Equal(t, codes.Unset, request.Status.Code)
+
+	for i, attempt := range []tracetest.SpanStub{first, second} {
+		assert.Equal(t, "Config API attempt", attempt.Name)
+		assert.Equal(t, request.SpanContext.SpanID(), attempt.Parent.SpanID())
+		assert.Equal(t, int64(i+1), spanAttribute(attempt, "segment.attempt").AsInt64())
+	}
+	assert.Equal(t, int64(http.StatusServiceUnavailable), spanAttribute(first, "http.status_code").AsInt64())
+	assert.True(t, spanAttribute(first, "segment.retryable").AsBool())
+	assert.Equal(t, codes.Error, first.Status.Code)
+	assert.Equal(t, int64(http.StatusOK), spanAttribute(second, "http.status_code").AsInt64())
+	assert.Equal(t, codes.Unset, second.Status.Code)
+}
+
+func TestClient_TracesFailedRequests(t *testing.T) {
+	exporter := recordSpans(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := configapi.NewClient("token", "myworkspace", configapi.WithBaseURL(server.URL))
+	_, err := client.GetWorkspace(context.Background())
+	require.Error(t, err)
+
+	ended := exporter.GetSpans()
+	require.Len(t, ended, 2)
+	attempt, request := ended[0], ended[1]
+	assert.False(t, spanAttribute(attempt, "segment.retryable").AsBool())
+	assert.Equal(t, codes.Error, request.Status.Code)
+	require.Len(t, request.Events, 1)
+	assert.Equal(t, "exception", request.Events[0].Name)
+}
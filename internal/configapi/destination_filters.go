@@ -0,0 +1,66 @@
+package configapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/uswitch/segment-config-go/segment"
+)
+
+var filterUpdateMask = segment.UpdateMask{Paths: []string{"if", "actions", "title", "description", "enabled"}}
+
+// ListDestinationFilters returns all filters of a destination
+func (c *Client) ListDestinationFilters(ctx context.Context, srcName string, destName string) ([]segment.DestinationFilter, error) {
+	var d destinationFiltersListResponse
+	if err := c.getJSON(ctx, c.destinationFiltersPath(srcName, destName), &d); err != nil {
+		return nil, err
+	}
+
+	return d.Filters, nil
+}
+
+// CreateDestinationFilter creates a new filter for a destination
+func (c *Client) CreateDestinationFilter(ctx context.Context, srcName string, destName string, filter segment.DestinationFilter) (*segment.DestinationFilter, error) {
+	var result segment.DestinationFilter
+	req := destinationFilterCRURequest{Filter: filter, UpdateMask: filterUpdateMask}
+	if err := c.sendJSON(ctx, http.MethodPost, c.destinationFiltersPath(srcName, destName), req, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// UpdateDestinationFilter updates an existing filter, identified by its full name
+func (c *Client) UpdateDestinationFilter(ctx context.Context, srcName string, destName string, filter segment.DestinationFilter) (*segment.DestinationFilter, error) {
+	var result segment.DestinationFilter
+	req := destinationFilterCRURequest{Filter: filter, UpdateMask: filterUpdateMask}
+	if err := c.sendJSON(ctx, http.MethodPatch, filter.Name, req, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetDestinationFilter returns information about a destination filter
+func (c *Client) GetDestinationFilter(ctx context.Context, srcName string, destName string, filterID string) (*segment.DestinationFilter, error) {
+	var filter segment.DestinationFilter
+	if err := c.getJSON(ctx, c.destinationFilterPath(srcName, destName, filterID), &filter); err != nil {
+		return nil, err
+	}
+
+	return &filter, nil
+}
+
+// DeleteDestinationFilter deletes a destination filter
+func (c *Client) DeleteDestinationFilter(ctx context.Context, srcName string, destName string, filterID string) error {
+	return c.sendJSON(ctx, http.MethodDelete, c.destinationFilterPath(srcName, destName, filterID), nil, nil)
+}
+
+func (c *Client) destinationFiltersPath(srcName string, destName string) string {
+	return fmt.Sprintf("%s/%s", c.destinationPath(srcName, destName), segment.DestinationFiltersEndpoint)
+}
+
+func (c *Client) destinationFilterPath(srcName string, destName string, filterID string) string {
+	return fmt.Sprintf("%s/%s", c.destinationFiltersPath(srcName, destName), filterID)
+}
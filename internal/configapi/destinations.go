@@ -0,0 +1,69 @@
+package configapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/uswitch/segment-config-go/segment"
+)
+
+// ListDestinations returns all destinations for a source
+func (c *Client) ListDestinations(ctx context.Context, srcName string) (segment.Destinations, error) {
+	var d segment.Destinations
+	err := c.getJSON(ctx, c.destinationsPath(srcName), &d)
+
+	return d, err
+}
+
+// GetDestination returns information about a destination for a source
+func (c *Client) GetDestination(ctx context.Context, srcName string, destName string) (segment.Destination, error) {
+	var d segment.Destination
+	err := c.getJSON(ctx, c.destinationPath(srcName, destName), &d)
+
+	return d, err
+}
+
+// CreateDestination creates a new destination for a source
+func (c *Client) CreateDestination(ctx context.Context, srcName string, destName string, connMode string, enabled bool, configs []segment.DestinationConfig) (segment.Destination, error) {
+	var d segment.Destination
+	req := destinationCreateRequest{segment.Destination{
+		Name:           c.destinationPath(srcName, destName),
+		ConnectionMode: connMode,
+		Enabled:        enabled,
+		Configs:        configs,
+	}}
+	err := c.sendJSON(ctx, http.MethodPost, c.destinationsPath(srcName), req, &d)
+
+	return d, err
+}
+
+// DeleteDestination deletes a destination for a source from the workspace
+func (c *Client) DeleteDestination(ctx context.Context, srcName string, destName string) error {
+	return c.sendJSON(ctx, http.MethodDelete, c.destinationPath(srcName, destName), nil, nil)
+}
+
+// UpdateDestination updates an existing destination with a new config
+func (c *Client) UpdateDestination(ctx context.Context, srcName string, destName string, enabled bool, configs []segment.DestinationConfig) (segment.Destination, error) {
+	var d segment.Destination
+	destFullName := c.destinationPath(srcName, destName)
+	req := destinationUpdateRequest{
+		Destination: segment.Destination{
+			Name:    destFullName,
+			Enabled: enabled,
+			Configs: configs,
+		},
+		UpdateMask: segment.UpdateMask{Paths: []string{"destination.config", "destination.enabled"}},
+	}
+	err := c.sendJSON(ctx, http.MethodPatch, destFullName, req, &d)
+
+	return d, err
+}
+
+func (c *Client) destinationsPath(srcName string) string {
+	return fmt.Sprintf("%s/%s", c.sourcePath(srcName), segment.DestinationEndpoint)
+}
+
+func (c *Client) destinationPath(srcName string, destName string) string {
+	return fmt.Sprintf("%s/%s", c.destinationsPath(srcName), destName)
+}
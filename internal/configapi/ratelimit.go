@@ -0,0 +1,65 @@
+package configapi
+
+import (
+	"context"
+	"sync/atomic"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimiter is a token bucket limiter shared by every request of the clients it is given to
+type RateLimiter struct {
+	limiter *rate.Limiter
+}
+
+// NewRateLimiter creates a limiter allowing requestsPerSecond requests per second on average, with bursts of up to burst
+// requests. A requestsPerSecond of 0 or less disables the limit.
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	limit := rate.Limit(requestsPerSecond)
+	if requestsPerSecond <= 0 {
+		limit = rate.Inf
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{limiter: rate.NewLimiter(limit, burst)}
+}
+
+// WithRateLimiter makes every request, including retries, wait for the limiter
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(c *Client) {
+		c.limiter = limiter
+	}
+}
+
+// Wait blocks until a request is allowed or the context is done. Requests which had to wait are recorded in the
+// throttle counter of the context, if any.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil || l.limiter.Allow() {
+		return nil
+	}
+
+	if counter, ok := ctx.Value(throttleCounterKey{}).(*int64); ok {
+		atomic.AddInt64(counter, 1)
+	}
+
+	return l.limiter.Wait(ctx)
+}
+
+type throttleCounterKey struct{}
+
+// WithThrottleCounter returns a context counting the requests throttled by a RateLimiter, see ThrottledCount
+func WithThrottleCounter(ctx context.Context) context.Context {
+	return context.WithValue(ctx, throttleCounterKey{}, new(int64))
+}
+
+// ThrottledCount returns how many requests made with the context have been throttled, or 0 if the context has no counter
+func ThrottledCount(ctx context.Context) int {
+	counter, ok := ctx.Value(throttleCounterKey{}).(*int64)
+	if !ok {
+		return 0
+	}
+
+	return int(atomic.LoadInt64(counter))
+}
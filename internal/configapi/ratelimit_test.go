@@ -0,0 +1,53 @@
+package configapi_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uswitch/terraform-provider-segment/internal/configapi"
+)
+
+func TestRateLimiter_ThrottlesRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	limiter := configapi.NewRateLimiter(20, 1)
+	client := configapi.NewClient("token", "myworkspace", configapi.WithBaseURL(server.URL), configapi.WithRateLimiter(limiter))
+
+	ctx := configapi.WithThrottleCounter(context.Background())
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := client.GetWorkspace(ctx)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, 2, configapi.ThrottledCount(ctx))
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+}
+
+func TestRateLimiter_Disabled(t *testing.T) {
+	limiter := configapi.NewRateLimiter(0, 1)
+
+	ctx := configapi.WithThrottleCounter(context.Background())
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, limiter.Wait(ctx))
+	}
+
+	assert.Equal(t, 0, configapi.ThrottledCount(ctx))
+}
+
+func TestRateLimiter_HonoursContext(t *testing.T) {
+	limiter := configapi.NewRateLimiter(0.001, 1)
+	assert.NoError(t, limiter.Wait(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.Error(t, limiter.Wait(ctx))
+}
@@ -0,0 +1,113 @@
+package configapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/uswitch/segment-config-go/segment"
+)
+
+// Source is a source of the workspace, with the attributes segment-config-go doesn't decode
+type Source struct {
+	segment.Source
+	// DisplayName is the name of the source in the Segment UI. It is nil when the API omits it, e.g. in the v1beta
+	// responses documented for Get Source.
+	DisplayName *string `json:"display_name,omitempty"`
+	// Enabled is whether Segment accepts the events sent to the source. It is nil when the API omits it, like
+	// DisplayName.
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// ListSources returns all sources for a workspace, following pagination
+func (c *Client) ListSources(ctx context.Context) ([]Source, error) {
+	var sources []Source
+	pageToken := ""
+	for {
+		endpoint := c.sourcesPath()
+		if pageToken != "" {
+			endpoint += "?page_token=" + url.QueryEscape(pageToken)
+		}
+
+		var page sourcesListResponse
+		if err := c.getJSON(ctx, endpoint, &page); err != nil {
+			return nil, err
+		}
+		sources = append(sources, page.Sources...)
+
+		if page.NextPageToken == "" {
+			return sources, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// GetSource returns information about a source
+func (c *Client) GetSource(ctx context.Context, srcName string) (Source, error) {
+	var s Source
+	err := c.getJSON(ctx, c.sourcePath(srcName), &s)
+
+	return s, err
+}
+
+// CreateSource creates a new source
+func (c *Client) CreateSource(ctx context.Context, srcName string, catName string) (Source, error) {
+	var s Source
+	req := sourceCreateRequest{segment.Source{
+		Name:        c.sourcePath(srcName),
+		CatalogName: catName,
+	}}
+	err := c.sendJSON(ctx, http.MethodPost, c.sourcesPath(), req, &s)
+
+	return s, err
+}
+
+// DeleteSource deletes a source from the workspace
+func (c *Client) DeleteSource(ctx context.Context, srcName string) error {
+	return c.sendJSON(ctx, http.MethodDelete, c.sourcePath(srcName), nil, nil)
+}
+
+// GetSourceConfig retrieves the schema config of a given source
+// API Doc: https://reference.segmentapis.com/#c74efb9b-b09e-4072-8da1-ba6ca60e6a78
+func (c *Client) GetSourceConfig(ctx context.Context, srcName string) (segment.SourceConfig, error) {
+	var result segment.SourceConfig
+	err := c.getJSON(ctx, c.sourcePath(srcName)+"/schema-config", &result)
+
+	return result, err
+}
+
+// UpdateSourceConfig updates the schema config of a given source
+// API Doc: https://reference.segmentapis.com/#af54244f-4ec7-4e78-96e9-8966dd18e56f
+func (c *Client) UpdateSourceConfig(ctx context.Context, srcName string, config segment.SourceConfig) (segment.SourceConfig, error) {
+	var result segment.SourceConfig
+	req := sourceConfigUpdateRequest{
+		Config: config,
+		UpdateMask: segment.UpdateMask{Paths: []string{
+			"schema_config.allow_unplanned_track_events",
+			"schema_config.allow_unplanned_identify_traits",
+			"schema_config.allow_unplanned_group_traits",
+			"schema_config.forwarding_blocked_events_to",
+			"schema_config.allow_unplanned_track_event_properties",
+			"schema_config.allow_track_event_on_violations",
+			"schema_config.allow_identify_traits_on_violations",
+			"schema_config.allow_group_traits_on_violations",
+			"schema_config.forwarding_violations_to",
+			"schema_config.allow_track_properties_on_violations",
+			"schema_config.common_track_event_on_violations",
+			"schema_config.common_identify_event_on_violations",
+			"schema_config.common_group_event_on_violations",
+		}},
+	}
+	err := c.sendJSON(ctx, http.MethodPatch, c.sourcePath(srcName)+"/schema-config", req, &result)
+
+	return result, err
+}
+
+func (c *Client) sourcesPath() string {
+	return fmt.Sprintf("%s/%s/%s", segment.WorkspacesEndpoint, c.workspace, segment.SourceEndpoint)
+}
+
+func (c *Client) sourcePath(srcName string) string {
+	return fmt.Sprintf("%s/%s", c.sourcesPath(), srcName)
+}
@@ -0,0 +1,46 @@
+package configapi_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uswitch/terraform-provider-segment/internal/configapi"
+)
+
+// getSourceResponse is a v1beta Get Source response, in the shape of the example of the Config API reference that
+// segment-config-go decodes. It has neither display_name nor enabled.
+const getSourceResponse = `{
+	"name": "workspaces/myworkspace/sources/js",
+	"parent": "workspaces/myworkspace",
+	"catalog_name": "catalog/sources/javascript",
+	"write_keys": [
+		"F4xIkfwZiNBeYI0A9y1Cikhgi9dy7gX7"
+	],
+	"library_config": {
+		"metrics_enabled": false,
+		"retry_queue": false,
+		"cross_domain_id_enabled": false,
+		"api_host": ""
+	},
+	"create_time": "2018-08-07T16:12:28.917Z"
+}`
+
+func TestClient_GetSourceWithoutDisplayNameNorEnabled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(getSourceResponse))
+	}))
+	defer server.Close()
+
+	client := configapi.NewClient("token", "myworkspace", configapi.WithBaseURL(server.URL))
+	src, err := client.GetSource(context.Background(), "js")
+
+	require.NoError(t, err)
+	assert.Equal(t, "catalog/sources/javascript", src.CatalogName)
+	assert.Equal(t, []string{"F4xIkfwZiNBeYI0A9y1Cikhgi9dy7gX7"}, src.WriteKeys)
+	assert.Nil(t, src.DisplayName, "a missing display_name shouldn't read as an empty one")
+	assert.Nil(t, src.Enabled, "a missing enabled shouldn't read as a disabled source")
+}
@@ -0,0 +1,114 @@
+package configapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/uswitch/terraform-provider-segment/internal/utils"
+)
+
+// TokenSource provides the access token sent with the requests of a client
+type TokenSource interface {
+	// Token returns the token to authenticate a request with
+	Token(ctx context.Context) (string, error)
+	// Invalidate is called when Segment rejects a token returned by Token. It returns whether a different token can be
+	// obtained, in which case the request is retried once.
+	Invalidate(token string) bool
+}
+
+// WithTokenSource authenticates requests with the tokens of a TokenSource instead of the access token of the client
+func WithTokenSource(tokens TokenSource) Option {
+	return func(c *Client) {
+		c.tokens = tokens
+	}
+}
+
+// StaticToken is a token which never changes, e.g. from the provider configuration
+type StaticToken string
+
+// Token returns the token
+func (t StaticToken) Token(context.Context) (string, error) {
+	return string(t), nil
+}
+
+// Invalidate returns false as there is no other token
+func (t StaticToken) Invalidate(string) bool {
+	return false
+}
+
+// CommandTokenSource gets tokens from an external command, e.g. the CLI of a secrets manager, which prints a token on
+// its standard output. The token is cached until Segment rejects it. It is safe for concurrent use.
+type CommandTokenSource struct {
+	command string
+
+	mu    sync.Mutex
+	token string
+}
+
+// NewCommandTokenSource creates a token source running the command with the shell of the system
+func NewCommandTokenSource(command string) *CommandTokenSource {
+	return &CommandTokenSource{command: command}
+}
+
+// Token returns the cached token, running the command if there is none
+func (s *CommandTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" {
+		return s.token, nil
+	}
+
+	token, err := s.run(ctx)
+	if err != nil {
+		return "", err
+	}
+	s.token = token
+
+	return token, nil
+}
+
+// Invalidate forgets the token, unless it has already been replaced, so the next call to Token runs the command again
+func (s *CommandTokenSource) Invalidate(token string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == token {
+		s.token = ""
+	}
+
+	return true
+}
+
+func (s *CommandTokenSource) run(ctx context.Context) (string, error) {
+	tflog.SubsystemInfo(ctx, utils.LogSegmentAPI, "Running the access token command")
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", s.command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", s.command)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		// The output isn't included as it may hold a secret
+		return "", fmt.Errorf("the access token command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	token := strings.TrimSpace(string(out))
+	if token == "" {
+		return "", errors.New("the access token command printed an empty token")
+	}
+
+	return token, nil
+}
@@ -0,0 +1,97 @@
+package configapi_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uswitch/terraform-provider-segment/internal/configapi"
+)
+
+// countingCommand returns a command printing token-<n>, where n is the number of times it has run, and a function
+// returning that number
+func countingCommand(t *testing.T) (string, func() int) {
+	runs := filepath.Join(t.TempDir(), "runs")
+	command := fmt.Sprintf(`echo run >> %[1]s && echo "token-$(wc -l < %[1]s | tr -d ' ')"`, runs)
+
+	return command, func() int {
+		b, err := os.ReadFile(runs)
+		if os.IsNotExist(err) {
+			return 0
+		}
+		require.NoError(t, err)
+		return strings.Count(string(b), "\n")
+	}
+}
+
+func TestCommandTokenSource_cachesToken(t *testing.T) {
+	command, runs := countingCommand(t)
+	tokens := configapi.NewCommandTokenSource(command)
+
+	for i := 0; i < 3; i++ {
+		token, err := tokens.Token(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "token-1", token)
+	}
+	assert.Equal(t, 1, runs())
+
+	assert.True(t, tokens.Invalidate("token-1"))
+	token, err := tokens.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", token)
+}
+
+func TestCommandTokenSource_failure(t *testing.T) {
+	tokens := configapi.NewCommandTokenSource("echo secrets manager unavailable >&2; exit 1")
+
+	_, err := tokens.Token(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "secrets manager unavailable")
+}
+
+func TestClient_RefreshesRejectedToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	command, runs := countingCommand(t)
+	client := configapi.NewClient("", "myworkspace",
+		configapi.WithBaseURL(server.URL),
+		configapi.WithTokenSource(configapi.NewCommandTokenSource(command)),
+	)
+
+	_, err := client.GetWorkspace(context.Background())
+	require.NoError(t, err)
+	_, err = client.GetWorkspace(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, runs(), "the token should be refreshed once, then cached")
+}
+
+func TestClient_DoesNotRetryRejectedStaticToken(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client := configapi.NewClient("token", "myworkspace", configapi.WithBaseURL(server.URL))
+	_, err := client.GetWorkspace(context.Background())
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, requests)
+}
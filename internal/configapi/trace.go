@@ -0,0 +1,161 @@
+package configapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/uswitch/terraform-provider-segment/internal/utils"
+)
+
+const redacted = "<redacted>"
+
+// tracingTransport logs the requests sent to the Config API and their responses to the segment_api subsystem, with
+// secrets redacted: the bearer token and the values of `password` destination configs
+type tracingTransport struct {
+	next  http.RoundTripper
+	level string
+}
+
+func (t *tracingTransport) log(ctx context.Context, msg string, fields map[string]interface{}) {
+	if strings.EqualFold(t.level, "INFO") {
+		tflog.SubsystemInfo(ctx, utils.LogSegmentAPI, msg, fields)
+	} else {
+		tflog.SubsystemDebug(ctx, utils.LogSegmentAPI, msg, fields)
+	}
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := requestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	fields := map[string]interface{}{
+		"method":          req.Method,
+		"path":            req.URL.Path,
+		"latency":         time.Since(start).String(),
+		"request_headers": redactHeaders(req.Header),
+		"request_body":    redactBody(reqBody),
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+		t.log(req.Context(), "Config API request failed", fields)
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	fields["status"] = resp.StatusCode
+	fields["response_body"] = redactBody(respBody)
+	t.log(req.Context(), "Config API request", fields)
+
+	return resp, nil
+}
+
+// requestBody returns a copy of the body of a request, leaving the request untouched
+func requestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+
+		return io.ReadAll(body)
+	}
+
+	b, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(b))
+
+	return b, nil
+}
+
+func redactHeaders(headers http.Header) string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		value := strings.Join(headers.Values(name), ", ")
+		if strings.EqualFold(name, "Authorization") {
+			value = "Bearer " + redacted
+		}
+		fmt.Fprintf(&b, "%s: %s\n", name, value)
+	}
+
+	return b.String()
+}
+
+// redactBody returns a JSON body with the values of password configs and the write keys of sources redacted. Bodies
+// which aren't JSON are returned as is, as the Config API only sends configs and sources as JSON.
+func redactBody(body []byte) string {
+	var decoded interface{}
+	if len(body) == 0 || json.Unmarshal(body, &decoded) != nil {
+		return string(body)
+	}
+
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(redactValue(decoded)); err != nil {
+		return string(body)
+	}
+
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+// redactValue replaces the value of every object typed as a password, e.g. a destination config, and every write key
+// of a source
+// Example:
+// 	{"name": "workspaces/.../config/apiKey", "type": "password", "value": "secret"}
+// 	{"name": "workspaces/.../sources/mysource", "write_keys": ["secret"]}
+func redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = redactValue(e)
+		}
+		if v["type"] == "password" {
+			if _, ok := v["value"]; ok {
+				v["value"] = redacted
+			}
+		}
+		if keys, ok := v["write_keys"].([]interface{}); ok {
+			for i := range keys {
+				keys[i] = redacted
+			}
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = redactValue(e)
+		}
+	}
+
+	return v
+}
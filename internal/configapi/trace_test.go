@@ -0,0 +1,101 @@
+package configapi_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uswitch/segment-config-go/segment"
+	"github.com/uswitch/terraform-provider-segment/internal/configapi"
+	"github.com/uswitch/terraform-provider-segment/internal/utils"
+)
+
+func TestNewHTTPClient_tracesRedactedRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name": "workspaces/myworkspace/sources/mysource/destinations/amplitude", "config": [` +
+			`{"name": "workspaces/myworkspace/sources/mysource/destinations/amplitude/config/apiKey", "type": "password", "value": "response-secret"},` +
+			`{"name": "workspaces/myworkspace/sources/mysource/destinations/amplitude/config/region", "type": "string", "value": "eu"}]}`))
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	ctx := utils.WithLogging(tflogtest.RootLogger(context.Background(), &logs), nil)
+
+	httpClient, err := configapi.NewHTTPClient(configapi.TransportConfig{Trace: true, TraceLevel: "DEBUG"})
+	require.NoError(t, err)
+	client := configapi.NewClient("bearer-secret", "myworkspace", configapi.WithBaseURL(server.URL), configapi.WithHTTPClient(httpClient))
+
+	_, err = client.CreateDestination(ctx, "mysource", "amplitude", "CLOUD", true, []segment.DestinationConfig{
+		{Name: "workspaces/myworkspace/sources/mysource/destinations/amplitude/config/apiKey", Type: "password", Value: "request-secret"},
+	})
+	require.NoError(t, err)
+
+	assert.NotContains(t, logs.String(), "bearer-secret")
+	assert.NotContains(t, logs.String(), "request-secret")
+	assert.NotContains(t, logs.String(), "response-secret")
+
+	entries, err := tflogtest.MultilineJSONDecode(&logs)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	trace := entries[0]
+	assert.Equal(t, "debug", trace["@level"])
+	assert.Equal(t, "provider.segment_api", trace["@module"])
+	assert.Equal(t, "POST", trace["method"])
+	assert.Equal(t, "/v1beta/workspaces/myworkspace/sources/mysource/destinations", trace["path"])
+	assert.Equal(t, float64(200), trace["status"])
+	assert.Contains(t, trace["request_headers"], "Authorization: Bearer <redacted>")
+	assert.Contains(t, trace["request_body"], `"value":"<redacted>"`)
+	assert.Contains(t, trace["response_body"], `"value":"<redacted>"`)
+	assert.Contains(t, trace["response_body"], `"value":"eu"`)
+}
+
+func TestNewHTTPClient_tracesRedactedWriteKeys(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"sources": [{"name": "workspaces/myworkspace/sources/mysource", "write_keys": ["wk-secret", "wk-rotated"]}]}`))
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	ctx := utils.WithLogging(tflogtest.RootLogger(context.Background(), &logs), nil)
+
+	httpClient, err := configapi.NewHTTPClient(configapi.TransportConfig{Trace: true, TraceLevel: "DEBUG"})
+	require.NoError(t, err)
+	client := configapi.NewClient("token", "myworkspace", configapi.WithBaseURL(server.URL), configapi.WithHTTPClient(httpClient))
+
+	sources, err := client.ListSources(ctx)
+	require.NoError(t, err)
+	require.Len(t, sources, 1)
+	assert.Equal(t, []string{"wk-secret", "wk-rotated"}, sources[0].WriteKeys, "only the trace should be redacted")
+
+	assert.NotContains(t, logs.String(), "wk-secret")
+	assert.NotContains(t, logs.String(), "wk-rotated")
+
+	entries, err := tflogtest.MultilineJSONDecode(&logs)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Contains(t, entries[0]["response_body"], `"write_keys":["<redacted>","<redacted>"]`)
+}
+
+func TestNewHTTPClient_doesNotTraceByDefault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	ctx := utils.WithLogging(tflogtest.RootLogger(context.Background(), &logs), nil)
+
+	httpClient, err := configapi.NewHTTPClient(configapi.TransportConfig{})
+	require.NoError(t, err)
+	client := configapi.NewClient("token", "myworkspace", configapi.WithBaseURL(server.URL), configapi.WithHTTPClient(httpClient))
+	_, err = client.GetWorkspace(ctx)
+	require.NoError(t, err)
+
+	assert.Empty(t, logs.String())
+}
@@ -0,0 +1,94 @@
+package configapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/uswitch/segment-config-go/segment"
+)
+
+// ListTrackingPlans lists all the tracking plans in the workspace
+func (c *Client) ListTrackingPlans(ctx context.Context) (segment.TrackingPlans, error) {
+	var tps segment.TrackingPlans
+	err := c.getJSON(ctx, c.trackingPlansPath(), &tps)
+
+	return tps, err
+}
+
+// GetTrackingPlan gets a specific tracking plan from segment
+func (c *Client) GetTrackingPlan(ctx context.Context, trackingPlanID string) (segment.TrackingPlan, error) {
+	var tp segment.TrackingPlan
+	err := c.getJSON(ctx, c.trackingPlanPath(trackingPlanID), &tp)
+
+	return tp, err
+}
+
+// CreateTrackingPlan creates a tracking plan
+func (c *Client) CreateTrackingPlan(ctx context.Context, data segment.TrackingPlan) (segment.TrackingPlan, error) {
+	var tp segment.TrackingPlan
+	err := c.sendJSON(ctx, http.MethodPost, c.trackingPlansPath(), trackingPlanCreateRequest{TrackingPlan: data}, &tp)
+
+	return tp, err
+}
+
+// UpdateTrackingPlan updates a tracking plan
+func (c *Client) UpdateTrackingPlan(ctx context.Context, trackingPlanID string, data segment.TrackingPlan) (segment.TrackingPlan, error) {
+	var tp segment.TrackingPlan
+	req := trackingPlanUpdateRequest{
+		UpdateMask:   segment.UpdateMask{Paths: []string{"tracking_plan.display_name", "tracking_plan.rules"}},
+		TrackingPlan: data,
+	}
+	err := c.sendJSON(ctx, http.MethodPut, c.trackingPlanPath(trackingPlanID), req, &tp)
+
+	return tp, err
+}
+
+// DeleteTrackingPlan deletes a tracking plan
+func (c *Client) DeleteTrackingPlan(ctx context.Context, trackingPlanID string) error {
+	return c.sendJSON(ctx, http.MethodDelete, c.trackingPlanPath(trackingPlanID), nil, nil)
+}
+
+// CreateTrackingPlanSourceConnection associates a source to a tracking plan
+// API Doc: https://reference.segmentapis.com/#8c794e32-86e5-4a81-96e1-dc30368f7a9e
+func (c *Client) CreateTrackingPlanSourceConnection(ctx context.Context, planID string, srcName string) error {
+	var result segment.TrackingPlanSourceConnection
+	req := trackingPlanSourceConnectionCreateRequest{Name: c.sourcePath(srcName)}
+
+	return c.sendJSON(ctx, http.MethodPost, c.trackingPlanPath(planID)+"/source-connections", req, &result)
+}
+
+// ListTrackingPlanSources lists all the sources associated with a given tracking plan
+// API Doc: https://reference.segmentapis.com/#27a50096-e444-48e6-abb5-6e9445740634
+func (c *Client) ListTrackingPlanSources(ctx context.Context, planID string) ([]segment.TrackingPlanSourceConnection, error) {
+	var connections segment.TrackingPlanSourceConnections
+	if err := c.getJSON(ctx, c.trackingPlanPath(planID)+"/source-connections", &connections); err != nil {
+		return nil, err
+	}
+
+	return connections.Connections, nil
+}
+
+// DeleteTrackingPlanSourceConnection removes the connection between a source and a tracking plan
+// API Doc: https://reference.segmentapis.com/#6d50bdb0-87fc-47b6-9169-5b022119fe2e
+func (c *Client) DeleteTrackingPlanSourceConnection(ctx context.Context, planID string, srcName string) error {
+	data, err := c.doRequest(ctx, http.MethodDelete, c.trackingPlanPath(planID)+"/source-connections/"+srcName, nil)
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(string(data)) != "{}" {
+		return fmt.Errorf("unexpected response body: %s", string(data))
+	}
+
+	return nil
+}
+
+func (c *Client) trackingPlansPath() string {
+	return fmt.Sprintf("%s/%s/%s", segment.WorkspacesEndpoint, c.workspace, segment.TrackingPlanEndpoint)
+}
+
+func (c *Client) trackingPlanPath(trackingPlanID string) string {
+	return fmt.Sprintf("%s/%s", c.trackingPlansPath(), trackingPlanID)
+}
@@ -0,0 +1,74 @@
+package configapi
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// TransportConfig describes how the HTTP client reaches the Config API
+type TransportConfig struct {
+	// ProxyURL is the proxy every request goes through. The standard proxy environment variables are used when empty.
+	ProxyURL string
+	// CACertFile is a PEM bundle trusted in addition to the system certificate pool
+	CACertFile string
+	// Timeout bounds each request, including reading the response body. Zero means no timeout.
+	Timeout time.Duration
+	// Trace logs every request and response, with secrets redacted
+	Trace bool
+	// TraceLevel is the log level of the traces, e.g. `DEBUG`
+	TraceLevel string
+}
+
+// NewHTTPClient builds an HTTP client according to the transport configuration
+func NewHTTPClient(config TransportConfig) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if config.ProxyURL != "" {
+		proxy, err := url.Parse(config.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL %s: %w", config.ProxyURL, err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if config.CACertFile != "" {
+		pool, err := loadCertPool(config.CACertFile)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	var roundTripper http.RoundTripper = transport
+	if config.Trace {
+		roundTripper = &tracingTransport{next: transport, level: config.TraceLevel}
+	}
+
+	return &http.Client{
+		Transport: roundTripper,
+		Timeout:   config.Timeout,
+	}, nil
+}
+
+func loadCertPool(caCertFile string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(caCertFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate file: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no valid PEM certificate found in %s", caCertFile)
+	}
+
+	return pool, nil
+}
@@ -0,0 +1,50 @@
+package configapi
+
+import "github.com/uswitch/segment-config-go/segment"
+
+// Request and response bodies which are not exported by segment-config-go
+
+type sourcesListResponse struct {
+	Sources       []Source `json:"sources"`
+	NextPageToken string   `json:"next_page_token"`
+}
+
+type sourceCreateRequest struct {
+	Source segment.Source `json:"source,omitempty"`
+}
+
+type sourceConfigUpdateRequest struct {
+	Config     segment.SourceConfig `json:"schema_config,omitempty"`
+	UpdateMask segment.UpdateMask   `json:"update_mask,omitempty"`
+}
+
+type destinationCreateRequest struct {
+	Destination segment.Destination `json:"destination,omitempty"`
+}
+
+type destinationUpdateRequest struct {
+	Destination segment.Destination `json:"destination,omitempty"`
+	UpdateMask  segment.UpdateMask  `json:"update_mask,omitempty"`
+}
+
+type destinationFiltersListResponse struct {
+	Filters []segment.DestinationFilter `json:"filters"`
+}
+
+type destinationFilterCRURequest struct {
+	Filter     segment.DestinationFilter `json:"filter"`
+	UpdateMask segment.UpdateMask        `json:"update_mask"`
+}
+
+type trackingPlanCreateRequest struct {
+	TrackingPlan segment.TrackingPlan `json:"tracking_plan,omitempty"`
+}
+
+type trackingPlanUpdateRequest struct {
+	UpdateMask   segment.UpdateMask   `json:"update_mask,omitempty"`
+	TrackingPlan segment.TrackingPlan `json:"tracking_plan,omitempty"`
+}
+
+type trackingPlanSourceConnectionCreateRequest struct {
+	Name string `json:"source_name"`
+}
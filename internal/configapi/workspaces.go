@@ -0,0 +1,16 @@
+package configapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/uswitch/segment-config-go/segment"
+)
+
+// GetWorkspace returns information about the client's workspace
+func (c *Client) GetWorkspace(ctx context.Context) (segment.Workspace, error) {
+	var w segment.Workspace
+	err := c.getJSON(ctx, fmt.Sprintf("%s/%s", segment.WorkspacesEndpoint, c.workspace), &w)
+
+	return w, err
+}
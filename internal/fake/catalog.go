@@ -0,0 +1,40 @@
+package fake
+
+import (
+	"context"
+
+	"github.com/uswitch/terraform-provider-segment/internal/configapi"
+)
+
+// AddCatalogEntry adds a type of source or destination to the catalog, which is empty otherwise
+func (w *Workspace) AddCatalogEntry(entry configapi.CatalogEntry) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.catalog[entry.Name] = entry
+}
+
+// GetCatalogSource returns a type of source of the catalog
+func (w *Workspace) GetCatalogSource(ctx context.Context, srcName string) (configapi.CatalogEntry, error) {
+	return w.getCatalogEntry(ctx, "GetCatalogSource", configapi.CatalogSourcePrefix+srcName)
+}
+
+// GetCatalogDestination returns a type of destination of the catalog
+func (w *Workspace) GetCatalogDestination(ctx context.Context, destName string) (configapi.CatalogEntry, error) {
+	return w.getCatalogEntry(ctx, "GetCatalogDestination", configapi.CatalogDestinationPrefix+destName)
+}
+
+func (w *Workspace) getCatalogEntry(ctx context.Context, method string, catName string) (configapi.CatalogEntry, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.call(ctx, method); err != nil {
+		return configapi.CatalogEntry{}, err
+	}
+
+	entry, ok := w.catalog[catName]
+	if !ok {
+		return configapi.CatalogEntry{}, notFound("catalog entry", catName)
+	}
+
+	return entry, nil
+}
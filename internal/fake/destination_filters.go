@@ -0,0 +1,107 @@
+package fake
+
+import (
+	"context"
+	"sort"
+
+	"github.com/uswitch/segment-config-go/segment"
+	"github.com/uswitch/terraform-provider-segment/internal/utils"
+)
+
+// ListDestinationFilters returns all filters of a destination, sorted by name
+func (w *Workspace) ListDestinationFilters(ctx context.Context, srcName string, destName string) ([]segment.DestinationFilter, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.call(ctx, "ListDestinationFilters"); err != nil {
+		return nil, err
+	}
+
+	if _, ok := w.destinations[srcName][destName]; !ok {
+		return nil, notFound("destination", destinationKey(srcName, destName))
+	}
+
+	result := []segment.DestinationFilter{}
+	for _, f := range w.filters[destinationKey(srcName, destName)] {
+		result = append(result, f)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	return result, nil
+}
+
+// GetDestinationFilter returns a destination filter
+func (w *Workspace) GetDestinationFilter(ctx context.Context, srcName string, destName string, filterID string) (*segment.DestinationFilter, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.call(ctx, "GetDestinationFilter"); err != nil {
+		return nil, err
+	}
+
+	f, ok := w.filters[destinationKey(srcName, destName)][filterID]
+	if !ok {
+		return nil, notFound("destination filter", filterID)
+	}
+
+	return &f, nil
+}
+
+// CreateDestinationFilter creates a filter for a destination, generating its ID
+func (w *Workspace) CreateDestinationFilter(ctx context.Context, srcName string, destName string, filter segment.DestinationFilter) (*segment.DestinationFilter, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.call(ctx, "CreateDestinationFilter"); err != nil {
+		return nil, err
+	}
+
+	if _, ok := w.destinations[srcName][destName]; !ok {
+		return nil, notFound("destination", destinationKey(srcName, destName))
+	}
+
+	id := w.nextID("df_")
+	filter.Name = w.filterName(srcName, destName, id)
+
+	key := destinationKey(srcName, destName)
+	if w.filters[key] == nil {
+		w.filters[key] = map[string]segment.DestinationFilter{}
+	}
+	w.filters[key][id] = filter
+
+	return &filter, nil
+}
+
+// UpdateDestinationFilter replaces a filter, identified by its full name
+func (w *Workspace) UpdateDestinationFilter(ctx context.Context, srcName string, destName string, filter segment.DestinationFilter) (*segment.DestinationFilter, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.call(ctx, "UpdateDestinationFilter"); err != nil {
+		return nil, err
+	}
+
+	id := utils.PathToName(filter.Name)
+	key := destinationKey(srcName, destName)
+	if _, ok := w.filters[key][id]; !ok || filter.Name != w.filterName(srcName, destName, id) {
+		return nil, notFound("destination filter", filter.Name)
+	}
+
+	w.filters[key][id] = filter
+
+	return &filter, nil
+}
+
+// DeleteDestinationFilter deletes a destination filter
+func (w *Workspace) DeleteDestinationFilter(ctx context.Context, srcName string, destName string, filterID string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.call(ctx, "DeleteDestinationFilter"); err != nil {
+		return err
+	}
+
+	key := destinationKey(srcName, destName)
+	if _, ok := w.filters[key][filterID]; !ok {
+		return notFound("destination filter", filterID)
+	}
+
+	delete(w.filters[key], filterID)
+
+	return nil
+}
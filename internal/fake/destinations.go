@@ -0,0 +1,150 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/uswitch/segment-config-go/segment"
+	"github.com/uswitch/terraform-provider-segment/internal/utils"
+)
+
+// ListDestinations returns all destinations of a source, sorted by name
+func (w *Workspace) ListDestinations(ctx context.Context, srcName string) (segment.Destinations, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.call(ctx, "ListDestinations"); err != nil {
+		return segment.Destinations{}, err
+	}
+
+	if _, ok := w.sources[srcName]; !ok {
+		return segment.Destinations{}, notFound("source", srcName)
+	}
+
+	result := segment.Destinations{}
+	for _, d := range w.destinations[srcName] {
+		result.Destinations = append(result.Destinations, d)
+	}
+	sort.Slice(result.Destinations, func(i, j int) bool { return result.Destinations[i].Name < result.Destinations[j].Name })
+
+	return result, nil
+}
+
+// GetDestination returns a destination
+func (w *Workspace) GetDestination(ctx context.Context, srcName string, destName string) (segment.Destination, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.call(ctx, "GetDestination"); err != nil {
+		return segment.Destination{}, err
+	}
+
+	d, ok := w.destinations[srcName][destName]
+	if !ok {
+		return segment.Destination{}, notFound("destination", destinationKey(srcName, destName))
+	}
+
+	return d, nil
+}
+
+// CreateDestination creates a destination for a source
+func (w *Workspace) CreateDestination(ctx context.Context, srcName string, destName string, connMode string, enabled bool, configs []segment.DestinationConfig) (segment.Destination, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.call(ctx, "CreateDestination"); err != nil {
+		return segment.Destination{}, err
+	}
+
+	if _, ok := w.sources[srcName]; !ok {
+		return segment.Destination{}, notFound("source", srcName)
+	}
+	if _, ok := w.destinations[srcName][destName]; ok {
+		return segment.Destination{}, alreadyExists("destination", destinationKey(srcName, destName))
+	}
+	if connMode == "" {
+		connMode = "UNSPECIFIED"
+	}
+
+	now := w.clock()
+	d := segment.Destination{
+		Name:           w.destinationName(srcName, destName),
+		Parent:         w.sourceName(srcName),
+		DisplayName:    destName,
+		Enabled:        enabled,
+		ConnectionMode: connMode,
+		CreateTime:     now,
+		UpdateTime:     now,
+	}
+	configs, err := w.destinationConfigs(srcName, destName, configs)
+	if err != nil {
+		return segment.Destination{}, err
+	}
+	d.Configs = configs
+
+	if w.destinations[srcName] == nil {
+		w.destinations[srcName] = map[string]segment.Destination{}
+	}
+	w.destinations[srcName][destName] = d
+
+	return d, nil
+}
+
+// UpdateDestination replaces the config and enabled state of a destination
+func (w *Workspace) UpdateDestination(ctx context.Context, srcName string, destName string, enabled bool, configs []segment.DestinationConfig) (segment.Destination, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.call(ctx, "UpdateDestination"); err != nil {
+		return segment.Destination{}, err
+	}
+
+	d, ok := w.destinations[srcName][destName]
+	if !ok {
+		return segment.Destination{}, notFound("destination", destinationKey(srcName, destName))
+	}
+
+	configs, err := w.destinationConfigs(srcName, destName, configs)
+	if err != nil {
+		return segment.Destination{}, err
+	}
+	d.Enabled = enabled
+	d.Configs = configs
+	d.UpdateTime = w.clock()
+	w.destinations[srcName][destName] = d
+
+	return d, nil
+}
+
+// DeleteDestination deletes a destination along with its filters
+func (w *Workspace) DeleteDestination(ctx context.Context, srcName string, destName string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.call(ctx, "DeleteDestination"); err != nil {
+		return err
+	}
+
+	if _, ok := w.destinations[srcName][destName]; !ok {
+		return notFound("destination", destinationKey(srcName, destName))
+	}
+
+	delete(w.filters, destinationKey(srcName, destName))
+	delete(w.destinations[srcName], destName)
+
+	return nil
+}
+
+// destinationConfigs validates that configs are named after the destination, as Segment does
+func (w *Workspace) destinationConfigs(srcName string, destName string, configs []segment.DestinationConfig) ([]segment.DestinationConfig, error) {
+	result := make([]segment.DestinationConfig, 0, len(configs))
+	for _, c := range configs {
+		expected := fmt.Sprintf("%s/config/%s", w.destinationName(srcName, destName), utils.PathToName(c.Name))
+		if c.Name != expected {
+			return nil, badRequest("invalid config name %s", c.Name)
+		}
+		if c.Type == "select" && c.Value == "" {
+			return nil, badRequest("invalid value for config %s: an option must be selected", c.Name)
+		}
+		result = append(result, c)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	return result, nil
+}
@@ -0,0 +1,173 @@
+package fake
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/uswitch/segment-config-go/segment"
+	"github.com/uswitch/terraform-provider-segment/internal/configapi"
+)
+
+// defaultSourceConfig is the schema config Segment assigns to new sources
+var defaultSourceConfig = segment.SourceConfig{
+	AllowUnplannedTrackEvents:           true,
+	AllowUnplannedIdentifyTraits:        true,
+	AllowUnplannedGroupTraits:           true,
+	AllowUnplannedTrackEventsProperties: true,
+	AllowIdentifyTraitsOnViolations:     true,
+	AllowGroupTraitsOnViolations:        true,
+	AllowTrackPropertiesOnViolations:    true,
+	CommonTrackEventOnViolations:        segment.Allow,
+	CommonIdentifyEventOnViolations:     segment.Allow,
+	CommonGroupEventOnViolations:        segment.Allow,
+}
+
+// ListSources returns all sources of the workspace, sorted by name
+func (w *Workspace) ListSources(ctx context.Context) ([]configapi.Source, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.call(ctx, "ListSources"); err != nil {
+		return nil, err
+	}
+
+	var result []configapi.Source
+	for _, s := range w.sources {
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	return result, nil
+}
+
+// GetSource returns a source
+func (w *Workspace) GetSource(ctx context.Context, srcName string) (configapi.Source, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.call(ctx, "GetSource"); err != nil {
+		return configapi.Source{}, err
+	}
+
+	s, ok := w.sources[srcName]
+	if !ok {
+		return configapi.Source{}, notFound("source", srcName)
+	}
+
+	return s, nil
+}
+
+// CreateSource creates a source
+func (w *Workspace) CreateSource(ctx context.Context, srcName string, catName string) (configapi.Source, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.call(ctx, "CreateSource"); err != nil {
+		return configapi.Source{}, err
+	}
+
+	if _, ok := w.sources[srcName]; ok {
+		return configapi.Source{}, alreadyExists("source", srcName)
+	}
+	if catName == "" {
+		return configapi.Source{}, badRequest("catalog_name is required")
+	}
+	if !strings.HasPrefix(catName, "catalog/sources/") {
+		return configapi.Source{}, notFound("catalog source", catName)
+	}
+
+	enabled := true
+	s := configapi.Source{
+		Source: segment.Source{
+			Name:        w.sourceName(srcName),
+			CatalogName: catName,
+			Parent:      w.workspaceName(),
+			WriteKeys:   []string{w.nextID("wk_")},
+			CreateTime:  w.clock(),
+		},
+		DisplayName: &srcName,
+		Enabled:     &enabled,
+	}
+	w.sources[srcName] = s
+	config := defaultSourceConfig
+	config.Name = w.sourceName(srcName) + "/schema-config"
+	config.Parent = w.sourceName(srcName)
+	w.sourceConfigs[srcName] = config
+
+	return s, nil
+}
+
+// EditSource changes the display name of a source and whether it is enabled, as done in the Segment UI
+func (w *Workspace) EditSource(srcName string, displayName string, enabled bool) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	s, ok := w.sources[srcName]
+	if !ok {
+		return notFound("source", srcName)
+	}
+
+	s.DisplayName = &displayName
+	s.Enabled = &enabled
+	w.sources[srcName] = s
+
+	return nil
+}
+
+// DeleteSource deletes a source along with its destinations, filters and tracking plan connection
+func (w *Workspace) DeleteSource(ctx context.Context, srcName string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.call(ctx, "DeleteSource"); err != nil {
+		return err
+	}
+
+	if _, ok := w.sources[srcName]; !ok {
+		return notFound("source", srcName)
+	}
+
+	for dest := range w.destinations[srcName] {
+		delete(w.filters, destinationKey(srcName, dest))
+	}
+	for _, sources := range w.connections {
+		delete(sources, srcName)
+	}
+	delete(w.destinations, srcName)
+	delete(w.sourceConfigs, srcName)
+	delete(w.sources, srcName)
+
+	return nil
+}
+
+// GetSourceConfig returns the schema config of a source
+func (w *Workspace) GetSourceConfig(ctx context.Context, srcName string) (segment.SourceConfig, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.call(ctx, "GetSourceConfig"); err != nil {
+		return segment.SourceConfig{}, err
+	}
+
+	config, ok := w.sourceConfigs[srcName]
+	if !ok {
+		return segment.SourceConfig{}, notFound("source", srcName)
+	}
+
+	return config, nil
+}
+
+// UpdateSourceConfig replaces the schema config of a source
+func (w *Workspace) UpdateSourceConfig(ctx context.Context, srcName string, config segment.SourceConfig) (segment.SourceConfig, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.call(ctx, "UpdateSourceConfig"); err != nil {
+		return segment.SourceConfig{}, err
+	}
+
+	if _, ok := w.sources[srcName]; !ok {
+		return segment.SourceConfig{}, notFound("source", srcName)
+	}
+
+	config.Name = w.sourceName(srcName) + "/schema-config"
+	config.Parent = w.sourceName(srcName)
+	w.sourceConfigs[srcName] = config
+
+	return config, nil
+}
@@ -0,0 +1,174 @@
+package fake
+
+import (
+	"context"
+	"sort"
+
+	"github.com/uswitch/segment-config-go/segment"
+)
+
+// ListTrackingPlans returns all tracking plans of the workspace, sorted by name
+func (w *Workspace) ListTrackingPlans(ctx context.Context) (segment.TrackingPlans, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.call(ctx, "ListTrackingPlans"); err != nil {
+		return segment.TrackingPlans{}, err
+	}
+
+	result := segment.TrackingPlans{}
+	for _, tp := range w.trackingPlans {
+		result.TrackingPlans = append(result.TrackingPlans, tp)
+	}
+	sort.Slice(result.TrackingPlans, func(i, j int) bool { return result.TrackingPlans[i].Name < result.TrackingPlans[j].Name })
+
+	return result, nil
+}
+
+// GetTrackingPlan returns a tracking plan
+func (w *Workspace) GetTrackingPlan(ctx context.Context, trackingPlanID string) (segment.TrackingPlan, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.call(ctx, "GetTrackingPlan"); err != nil {
+		return segment.TrackingPlan{}, err
+	}
+
+	tp, ok := w.trackingPlans[trackingPlanID]
+	if !ok {
+		return segment.TrackingPlan{}, notFound("tracking plan", trackingPlanID)
+	}
+
+	return tp, nil
+}
+
+// CreateTrackingPlan creates a tracking plan, generating its ID
+func (w *Workspace) CreateTrackingPlan(ctx context.Context, data segment.TrackingPlan) (segment.TrackingPlan, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.call(ctx, "CreateTrackingPlan"); err != nil {
+		return segment.TrackingPlan{}, err
+	}
+
+	if data.DisplayName == "" {
+		return segment.TrackingPlan{}, badRequest("display_name is required")
+	}
+
+	id := w.nextID("rs_")
+	now := w.clock()
+	tp := segment.TrackingPlan{
+		Name:        w.trackingPlanName(id),
+		DisplayName: data.DisplayName,
+		Rules:       data.Rules,
+		CreateTime:  now,
+		UpdateTime:  now,
+	}
+	w.trackingPlans[id] = tp
+	w.connections[id] = map[string]bool{}
+
+	return tp, nil
+}
+
+// UpdateTrackingPlan replaces the display name and rules of a tracking plan
+func (w *Workspace) UpdateTrackingPlan(ctx context.Context, trackingPlanID string, data segment.TrackingPlan) (segment.TrackingPlan, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.call(ctx, "UpdateTrackingPlan"); err != nil {
+		return segment.TrackingPlan{}, err
+	}
+
+	tp, ok := w.trackingPlans[trackingPlanID]
+	if !ok {
+		return segment.TrackingPlan{}, notFound("tracking plan", trackingPlanID)
+	}
+
+	tp.DisplayName = data.DisplayName
+	tp.Rules = data.Rules
+	tp.UpdateTime = w.clock()
+	w.trackingPlans[trackingPlanID] = tp
+
+	return tp, nil
+}
+
+// DeleteTrackingPlan deletes a tracking plan along with its source connections
+func (w *Workspace) DeleteTrackingPlan(ctx context.Context, trackingPlanID string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.call(ctx, "DeleteTrackingPlan"); err != nil {
+		return err
+	}
+
+	if _, ok := w.trackingPlans[trackingPlanID]; !ok {
+		return notFound("tracking plan", trackingPlanID)
+	}
+
+	delete(w.connections, trackingPlanID)
+	delete(w.trackingPlans, trackingPlanID)
+
+	return nil
+}
+
+// CreateTrackingPlanSourceConnection connects a source to a tracking plan. A source can only be connected to one tracking plan.
+func (w *Workspace) CreateTrackingPlanSourceConnection(ctx context.Context, planID string, srcName string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.call(ctx, "CreateTrackingPlanSourceConnection"); err != nil {
+		return err
+	}
+
+	if _, ok := w.trackingPlans[planID]; !ok {
+		return notFound("tracking plan", planID)
+	}
+	if _, ok := w.sources[srcName]; !ok {
+		return notFound("source", srcName)
+	}
+	for tp, sources := range w.connections {
+		if sources[srcName] {
+			return badRequest("source %s is already connected to tracking plan %s", srcName, tp)
+		}
+	}
+
+	w.connections[planID][srcName] = true
+
+	return nil
+}
+
+// ListTrackingPlanSources lists the connections of a tracking plan, sorted by source name
+func (w *Workspace) ListTrackingPlanSources(ctx context.Context, planID string) ([]segment.TrackingPlanSourceConnection, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.call(ctx, "ListTrackingPlanSources"); err != nil {
+		return nil, err
+	}
+
+	sources, ok := w.connections[planID]
+	if !ok {
+		return nil, notFound("tracking plan", planID)
+	}
+
+	result := []segment.TrackingPlanSourceConnection{}
+	for src := range sources {
+		result = append(result, segment.TrackingPlanSourceConnection{
+			Source:         w.sourceName(src),
+			TrackingPlanId: planID,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Source < result[j].Source })
+
+	return result, nil
+}
+
+// DeleteTrackingPlanSourceConnection disconnects a source from a tracking plan
+func (w *Workspace) DeleteTrackingPlanSourceConnection(ctx context.Context, planID string, srcName string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.call(ctx, "DeleteTrackingPlanSourceConnection"); err != nil {
+		return err
+	}
+
+	if !w.connections[planID][srcName] {
+		return notFound("tracking plan source connection", planID+"/"+srcName)
+	}
+
+	delete(w.connections[planID], srcName)
+
+	return nil
+}
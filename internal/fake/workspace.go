@@ -0,0 +1,194 @@
+// Package fake provides an in-memory Segment workspace implementing the Config API operations used by the provider.
+// It is meant for unit tests: resources are stored with the same naming scheme as Segment
+// (e.g. `workspaces/myworkspace/sources/mysource`) and missing resources are reported with 404 errors.
+package fake
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/uswitch/segment-config-go/segment"
+	"github.com/uswitch/terraform-provider-segment/internal/configapi"
+)
+
+// Fault describes an error to return instead of performing an operation
+type Fault struct {
+	// Method is the name of the operation to fail, e.g. `GetSource`. An empty method matches every operation.
+	Method string
+	// Code is the HTTP status code of the returned *segment.SegmentApiError
+	Code int
+	// Times is the number of calls to fail before the fault is cleared
+	Times int
+	// After is the number of matching calls to let through before failing, e.g. to fail the read following a create
+	After int
+}
+
+// Workspace is an in-memory Segment workspace. It is safe for concurrent use.
+type Workspace struct {
+	mu      sync.Mutex
+	slug    string
+	clock   func() time.Time
+	seq     int
+	latency time.Duration
+
+	sources       map[string]configapi.Source
+	sourceConfigs map[string]segment.SourceConfig
+	destinations  map[string]map[string]segment.Destination
+	filters       map[string]map[string]segment.DestinationFilter
+	trackingPlans map[string]segment.TrackingPlan
+	catalog       map[string]configapi.CatalogEntry
+	connections   map[string]map[string]bool
+
+	faults []*Fault
+	calls  map[string]int
+}
+
+// NewWorkspace creates an empty workspace
+func NewWorkspace(slug string) *Workspace {
+	return &Workspace{
+		slug:          slug,
+		clock:         time.Now,
+		sources:       map[string]configapi.Source{},
+		sourceConfigs: map[string]segment.SourceConfig{},
+		destinations:  map[string]map[string]segment.Destination{},
+		filters:       map[string]map[string]segment.DestinationFilter{},
+		trackingPlans: map[string]segment.TrackingPlan{},
+		catalog:       map[string]configapi.CatalogEntry{},
+		connections:   map[string]map[string]bool{},
+		calls:         map[string]int{},
+	}
+}
+
+// Slug returns the workspace slug
+func (w *Workspace) Slug() string {
+	return w.slug
+}
+
+// InjectFault makes the next calls matching the fault fail
+func (w *Workspace) InjectFault(f Fault) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.faults = append(w.faults, &f)
+}
+
+// SetLatency makes every operation take at least the given duration, to simulate a remote API
+func (w *Workspace) SetLatency(latency time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.latency = latency
+}
+
+// CallCount returns how many times an operation has been called, or the total number of calls for an empty method
+func (w *Workspace) CallCount(method string) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if method == "" {
+		total := 0
+		for _, n := range w.calls {
+			total += n
+		}
+		return total
+	}
+
+	return w.calls[method]
+}
+
+// GetWorkspace returns information about the workspace
+func (w *Workspace) GetWorkspace(ctx context.Context) (segment.Workspace, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.call(ctx, "GetWorkspace"); err != nil {
+		return segment.Workspace{}, err
+	}
+
+	return segment.Workspace{Name: w.workspaceName(), DisplayName: w.slug, ID: w.slug}, nil
+}
+
+// call records an operation and returns the error it should fail with, if any. It must be called with the lock held,
+// which is released while simulating latency.
+func (w *Workspace) call(ctx context.Context, method string) error {
+	if w.latency > 0 {
+		w.mu.Unlock()
+		select {
+		case <-ctx.Done():
+		case <-time.After(w.latency):
+		}
+		w.mu.Lock()
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	w.calls[method]++
+
+	for i, f := range w.faults {
+		if f.Method != "" && f.Method != method {
+			continue
+		}
+		if f.After > 0 {
+			f.After--
+			continue
+		}
+
+		f.Times--
+		if f.Times <= 0 {
+			w.faults = append(w.faults[:i], w.faults[i+1:]...)
+		}
+
+		return &segment.SegmentApiError{Code: f.Code, Message: fmt.Sprintf("injected fault for %s", method)}
+	}
+
+	return nil
+}
+
+func (w *Workspace) nextID(prefix string) string {
+	w.seq++
+	return fmt.Sprintf("%s%d", prefix, w.seq)
+}
+
+// Errors
+
+func notFound(kind string, name string) error {
+	return &segment.SegmentApiError{Code: http.StatusNotFound, Message: fmt.Sprintf("%s %s not found", kind, name)}
+}
+
+func alreadyExists(kind string, name string) error {
+	return &segment.SegmentApiError{Code: http.StatusBadRequest, Message: fmt.Sprintf("%s %s already exists", kind, name)}
+}
+
+func badRequest(format string, args ...interface{}) error {
+	return &segment.SegmentApiError{Code: http.StatusBadRequest, Message: fmt.Sprintf(format, args...)}
+}
+
+// Names
+
+func (w *Workspace) workspaceName() string {
+	return fmt.Sprintf("%s/%s", segment.WorkspacesEndpoint, w.slug)
+}
+
+func (w *Workspace) sourceName(src string) string {
+	return fmt.Sprintf("%s/%s/%s", w.workspaceName(), segment.SourceEndpoint, src)
+}
+
+func (w *Workspace) destinationName(src string, dest string) string {
+	return fmt.Sprintf("%s/%s/%s", w.sourceName(src), segment.DestinationEndpoint, dest)
+}
+
+func (w *Workspace) filterName(src string, dest string, id string) string {
+	return fmt.Sprintf("%s/%s/%s", w.destinationName(src, dest), segment.DestinationFiltersEndpoint, id)
+}
+
+func (w *Workspace) trackingPlanName(id string) string {
+	return fmt.Sprintf("%s/%s/%s", w.workspaceName(), segment.TrackingPlanEndpoint, id)
+}
+
+func destinationKey(src string, dest string) string {
+	return src + "/" + dest
+}
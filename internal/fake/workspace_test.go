@@ -0,0 +1,87 @@
+package fake_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uswitch/segment-config-go/segment"
+	"github.com/uswitch/terraform-provider-segment/internal/fake"
+	"github.com/uswitch/terraform-provider-segment/internal/utils"
+)
+
+func TestWorkspace_NotFound(t *testing.T) {
+	ws := fake.NewWorkspace("ws")
+
+	_, err := ws.GetSource(context.Background(), "missing")
+
+	assertAPIError(t, err, http.StatusNotFound)
+}
+
+func TestWorkspace_InjectFault(t *testing.T) {
+	ctx := context.Background()
+	ws := fake.NewWorkspace("ws")
+	ws.InjectFault(fake.Fault{Method: "CreateSource", Code: http.StatusTooManyRequests, Times: 2})
+
+	_, err := ws.ListSources(ctx)
+	require.NoError(t, err, "faults only apply to the matching method")
+
+	for i := 0; i < 2; i++ {
+		_, err = ws.CreateSource(ctx, "src", "catalog/sources/javascript")
+		assertAPIError(t, err, http.StatusTooManyRequests)
+	}
+
+	src, err := ws.CreateSource(ctx, "src", "catalog/sources/javascript")
+	require.NoError(t, err)
+	assert.Equal(t, "workspaces/ws/sources/src", src.Name)
+	assert.Equal(t, 3, ws.CallCount("CreateSource"))
+	assert.Equal(t, 4, ws.CallCount(""))
+}
+
+func TestWorkspace_InjectFaultAfter(t *testing.T) {
+	ctx := context.Background()
+	ws := fake.NewWorkspace("ws")
+	ws.InjectFault(fake.Fault{Method: "GetSource", Code: http.StatusBadGateway, Times: 1, After: 1})
+	_, err := ws.CreateSource(ctx, "src", "catalog/sources/javascript")
+	require.NoError(t, err)
+
+	_, err = ws.GetSource(ctx, "src")
+	require.NoError(t, err, "the first call should be let through")
+	_, err = ws.GetSource(ctx, "src")
+	assertAPIError(t, err, http.StatusBadGateway)
+	_, err = ws.GetSource(ctx, "src")
+	assert.NoError(t, err)
+}
+
+func TestWorkspace_DeleteSourceCascades(t *testing.T) {
+	ctx := context.Background()
+	ws := fake.NewWorkspace("ws")
+	_, err := ws.CreateSource(ctx, "src", "catalog/sources/javascript")
+	require.NoError(t, err)
+	_, err = ws.CreateDestination(ctx, "src", "dest", "CLOUD", true, nil)
+	require.NoError(t, err)
+	tp, err := ws.CreateTrackingPlan(ctx, segment.TrackingPlan{DisplayName: "plan"})
+	require.NoError(t, err)
+	tpID := utils.PathToName(tp.Name)
+	require.NoError(t, ws.CreateTrackingPlanSourceConnection(ctx, tpID, "src"))
+
+	require.NoError(t, ws.DeleteSource(ctx, "src"))
+
+	_, err = ws.GetDestination(ctx, "src", "dest")
+	assertAPIError(t, err, http.StatusNotFound)
+	connections, err := ws.ListTrackingPlanSources(ctx, tpID)
+	require.NoError(t, err)
+	assert.Empty(t, connections)
+}
+
+func assertAPIError(t *testing.T, err error, code int) {
+	t.Helper()
+
+	var apiErr *segment.SegmentApiError
+	if assert.True(t, errors.As(err, &apiErr), "expected a Segment API error, got %v", err) {
+		assert.Equal(t, code, apiErr.Code)
+	}
+}
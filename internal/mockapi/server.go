@@ -0,0 +1,312 @@
+// Package mockapi provides a local Segment Config API server for running the acceptance tests offline.
+//
+// The server keeps its state in a fake.Workspace, so it behaves the same way as the in-memory fake used by unit tests,
+// and faults (e.g. 429 or 5xx responses) can be injected on demand.
+package mockapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+
+	"github.com/uswitch/segment-config-go/segment"
+	"github.com/uswitch/terraform-provider-segment/internal/fake"
+	"github.com/uswitch/terraform-provider-segment/internal/utils"
+)
+
+const apiPrefix = "/v1beta/"
+
+// Server is a Segment Config API server for a single workspace
+type Server struct {
+	*httptest.Server
+
+	// Workspace holds the state of the server
+	Workspace *fake.Workspace
+	// Token is the access token expected in requests
+	Token string
+	// PageSize is the number of items in each page of list responses
+	PageSize int
+}
+
+// defaultPageSize is the page size of the Config API
+const defaultPageSize = 10
+
+// NewServer starts a server for the given workspace slug, accepting the given access token
+func NewServer(workspace string, token string) *Server {
+	s := &Server{
+		Workspace: fake.NewWorkspace(workspace),
+		Token:     token,
+		PageSize:  defaultPageSize,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// InjectFault makes the next matching calls fail with the status code of the fault
+func (s *Server) InjectFault(f fake.Fault) {
+	s.Workspace.InjectFault(f)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Printf("[DEBUG] Mock Config API: %s %s", r.Method, r.URL.Path)
+
+	if r.Header.Get("Authorization") != "Bearer "+s.Token {
+		writeError(w, &segment.SegmentApiError{Code: http.StatusUnauthorized, Message: "invalid access token"})
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, apiPrefix), "/"), "/")
+	var result interface{}
+	var err error
+	switch {
+	case !strings.HasPrefix(r.URL.Path, apiPrefix) || len(parts) < 2:
+		err = notFound(r)
+	case parts[0] == "catalog":
+		result, err = s.routeCatalog(r, parts[1:])
+	case parts[0] != segment.WorkspacesEndpoint || parts[1] != s.Workspace.Slug():
+		err = notFound(r)
+	default:
+		result, err = s.route(r, parts[2:])
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		log.Printf("[ERROR] Mock Config API: failed to encode response: %s", err)
+	}
+}
+
+// route dispatches a request to the workspace according to the path following `workspaces/<slug>`
+func (s *Server) route(r *http.Request, path []string) (interface{}, error) {
+	ctx := r.Context()
+	ws := s.Workspace
+	resource := strings.Join(routePattern(path), "/")
+
+	switch {
+	case resource == "" && r.Method == http.MethodGet:
+		return ws.GetWorkspace(ctx)
+
+	// Sources
+	case resource == "sources" && r.Method == http.MethodGet:
+		sources, err := ws.ListSources(ctx)
+		if err != nil {
+			return nil, err
+		}
+		start, end, next, err := s.page(r, len(sources))
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"sources": sources[start:end], "next_page_token": next}, nil
+	case resource == "sources" && r.Method == http.MethodPost:
+		var req struct {
+			Source segment.Source `json:"source"`
+		}
+		if err := decode(r, &req); err != nil {
+			return nil, err
+		}
+		return ws.CreateSource(ctx, utils.PathToName(req.Source.Name), req.Source.CatalogName)
+	case resource == "sources/*" && r.Method == http.MethodGet:
+		return ws.GetSource(ctx, path[1])
+	case resource == "sources/*" && r.Method == http.MethodDelete:
+		return empty(ws.DeleteSource(ctx, path[1]))
+	case resource == "sources/*/schema-config" && r.Method == http.MethodGet:
+		return ws.GetSourceConfig(ctx, path[1])
+	case resource == "sources/*/schema-config" && r.Method == http.MethodPatch:
+		var req struct {
+			Config segment.SourceConfig `json:"schema_config"`
+		}
+		if err := decode(r, &req); err != nil {
+			return nil, err
+		}
+		return ws.UpdateSourceConfig(ctx, path[1], req.Config)
+
+	// Destinations
+	case resource == "sources/*/destinations" && r.Method == http.MethodGet:
+		return ws.ListDestinations(ctx, path[1])
+	case resource == "sources/*/destinations" && r.Method == http.MethodPost:
+		var req struct {
+			Destination segment.Destination `json:"destination"`
+		}
+		if err := decode(r, &req); err != nil {
+			return nil, err
+		}
+		d := req.Destination
+		return ws.CreateDestination(ctx, path[1], utils.PathToName(d.Name), d.ConnectionMode, d.Enabled, d.Configs)
+	case resource == "sources/*/destinations/*" && r.Method == http.MethodGet:
+		return ws.GetDestination(ctx, path[1], path[3])
+	case resource == "sources/*/destinations/*" && r.Method == http.MethodPatch:
+		var req struct {
+			Destination segment.Destination `json:"destination"`
+		}
+		if err := decode(r, &req); err != nil {
+			return nil, err
+		}
+		return ws.UpdateDestination(ctx, path[1], path[3], req.Destination.Enabled, req.Destination.Configs)
+	case resource == "sources/*/destinations/*" && r.Method == http.MethodDelete:
+		return empty(ws.DeleteDestination(ctx, path[1], path[3]))
+
+	// Destination filters
+	case resource == "sources/*/destinations/*/filters" && r.Method == http.MethodGet:
+		filters, err := ws.ListDestinationFilters(ctx, path[1], path[3])
+		return map[string]interface{}{"filters": filters}, err
+	case resource == "sources/*/destinations/*/filters" && r.Method == http.MethodPost:
+		filter, err := decodeFilter(r)
+		if err != nil {
+			return nil, err
+		}
+		return ws.CreateDestinationFilter(ctx, path[1], path[3], filter)
+	case resource == "sources/*/destinations/*/filters/*" && r.Method == http.MethodGet:
+		return ws.GetDestinationFilter(ctx, path[1], path[3], path[5])
+	case resource == "sources/*/destinations/*/filters/*" && r.Method == http.MethodPatch:
+		filter, err := decodeFilter(r)
+		if err != nil {
+			return nil, err
+		}
+		return ws.UpdateDestinationFilter(ctx, path[1], path[3], filter)
+	case resource == "sources/*/destinations/*/filters/*" && r.Method == http.MethodDelete:
+		return empty(ws.DeleteDestinationFilter(ctx, path[1], path[3], path[5]))
+
+	// Tracking plans
+	case resource == "tracking-plans" && r.Method == http.MethodGet:
+		return ws.ListTrackingPlans(ctx)
+	case resource == "tracking-plans" && r.Method == http.MethodPost:
+		var req struct {
+			TrackingPlan segment.TrackingPlan `json:"tracking_plan"`
+		}
+		if err := decode(r, &req); err != nil {
+			return nil, err
+		}
+		return ws.CreateTrackingPlan(ctx, req.TrackingPlan)
+	case resource == "tracking-plans/*" && r.Method == http.MethodGet:
+		return ws.GetTrackingPlan(ctx, path[1])
+	case resource == "tracking-plans/*" && r.Method == http.MethodPut:
+		var req struct {
+			TrackingPlan segment.TrackingPlan `json:"tracking_plan"`
+		}
+		if err := decode(r, &req); err != nil {
+			return nil, err
+		}
+		return ws.UpdateTrackingPlan(ctx, path[1], req.TrackingPlan)
+	case resource == "tracking-plans/*" && r.Method == http.MethodDelete:
+		return empty(ws.DeleteTrackingPlan(ctx, path[1]))
+
+	// Tracking plan source connections
+	case resource == "tracking-plans/*/source-connections" && r.Method == http.MethodGet:
+		connections, err := ws.ListTrackingPlanSources(ctx, path[1])
+		return segment.TrackingPlanSourceConnections{Connections: connections}, err
+	case resource == "tracking-plans/*/source-connections" && r.Method == http.MethodPost:
+		var req struct {
+			Name string `json:"source_name"`
+		}
+		if err := decode(r, &req); err != nil {
+			return nil, err
+		}
+		src := utils.PathToName(req.Name)
+		if err := ws.CreateTrackingPlanSourceConnection(ctx, path[1], src); err != nil {
+			return nil, err
+		}
+		return segment.TrackingPlanSourceConnection{Source: req.Name, TrackingPlanId: path[1]}, nil
+	case resource == "tracking-plans/*/source-connections/*" && r.Method == http.MethodDelete:
+		return empty(ws.DeleteTrackingPlanSourceConnection(ctx, path[1], path[3]))
+	}
+
+	return nil, notFound(r)
+}
+
+// page returns the bounds of the page of a list requested with the `page_token` query parameter, along with the token
+// of the next page, if any. Tokens are offsets in the list.
+func (s *Server) page(r *http.Request, total int) (start int, end int, next string, err error) {
+	if token := r.URL.Query().Get("page_token"); token != "" {
+		start, err = strconv.Atoi(token)
+		if err != nil || start < 0 || start > total {
+			return 0, 0, "", &segment.SegmentApiError{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid page token %q", token)}
+		}
+	}
+
+	end = total
+	if s.PageSize > 0 && start+s.PageSize < total {
+		end = start + s.PageSize
+		next = strconv.Itoa(end)
+	}
+
+	return start, end, next, nil
+}
+
+// routeCatalog dispatches a request to the catalog according to the path following `catalog`, which doesn't belong
+// to a workspace
+func (s *Server) routeCatalog(r *http.Request, path []string) (interface{}, error) {
+	ctx := r.Context()
+	resource := strings.Join(routePattern(path), "/")
+
+	switch {
+	case resource == "sources/*" && r.Method == http.MethodGet:
+		return s.Workspace.GetCatalogSource(ctx, path[1])
+	case resource == "destinations/*" && r.Method == http.MethodGet:
+		return s.Workspace.GetCatalogDestination(ctx, path[1])
+	}
+
+	return nil, notFound(r)
+}
+
+// routePattern replaces the identifiers of a path with `*`, e.g. sources/foo/destinations => sources/*/destinations
+func routePattern(path []string) []string {
+	pattern := make([]string, len(path))
+	for i, p := range path {
+		if i%2 == 1 {
+			p = "*"
+		}
+		pattern[i] = p
+	}
+
+	return pattern
+}
+
+func decode(r *http.Request, dst interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(dst); err != nil {
+		return &segment.SegmentApiError{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid request body: %s", err)}
+	}
+
+	return nil
+}
+
+func decodeFilter(r *http.Request) (segment.DestinationFilter, error) {
+	var req struct {
+		Filter segment.DestinationFilter `json:"filter"`
+	}
+	err := decode(r, &req)
+
+	return req.Filter, err
+}
+
+// empty is the response of deletions
+func empty(err error) (interface{}, error) {
+	return struct{}{}, err
+}
+
+func notFound(r *http.Request) error {
+	return &segment.SegmentApiError{Code: http.StatusNotFound, Message: fmt.Sprintf("no route for %s %s", r.Method, r.URL.Path)}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	code, message := http.StatusInternalServerError, err.Error()
+	var apiErr *segment.SegmentApiError
+	if errors.As(err, &apiErr) {
+		code, message = apiErr.Code, apiErr.Message
+	} else if errors.Is(err, context.Canceled) {
+		code = 499
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(segment.SegmentApiError{Code: code, Message: message})
+}
@@ -0,0 +1,186 @@
+package mockapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uswitch/segment-config-go/segment"
+	"github.com/uswitch/terraform-provider-segment/internal/configapi"
+	"github.com/uswitch/terraform-provider-segment/internal/fake"
+	"github.com/uswitch/terraform-provider-segment/internal/mockapi"
+	"github.com/uswitch/terraform-provider-segment/internal/utils"
+)
+
+func newClient(t *testing.T) (*mockapi.Server, *configapi.Client) {
+	server := mockapi.NewServer("ws", "token")
+	t.Cleanup(server.Close)
+
+	return server, configapi.NewClient("token", "ws", configapi.WithBaseURL(server.URL))
+}
+
+func TestServer_SourcesAndDestinations(t *testing.T) {
+	ctx := context.Background()
+	_, client := newClient(t)
+
+	_, err := client.CreateSource(ctx, "src", "catalog/sources/javascript")
+	require.NoError(t, err)
+	sources, err := client.ListSources(ctx)
+	require.NoError(t, err)
+	require.Len(t, sources, 1)
+	assert.Equal(t, "workspaces/ws/sources/src", sources[0].Name)
+
+	src, err := client.GetSource(ctx, "src")
+	require.NoError(t, err)
+	require.NotNil(t, src.DisplayName)
+	assert.Equal(t, "src", *src.DisplayName)
+
+	config := segment.SourceConfig{AllowUnplannedTrackEvents: true, CommonTrackEventOnViolations: segment.Block}
+	_, err = client.UpdateSourceConfig(ctx, "src", config)
+	require.NoError(t, err)
+	updated, err := client.GetSourceConfig(ctx, "src")
+	require.NoError(t, err)
+	assert.Equal(t, segment.Block, updated.CommonTrackEventOnViolations)
+
+	configs := []segment.DestinationConfig{{
+		Name:  "workspaces/ws/sources/src/destinations/webhooks/config/hooks",
+		Type:  "string",
+		Value: "https://example.com",
+	}}
+	_, err = client.CreateDestination(ctx, "src", "webhooks", "CLOUD", true, configs)
+	require.NoError(t, err)
+	_, err = client.UpdateDestination(ctx, "src", "webhooks", false, configs)
+	require.NoError(t, err)
+	dest, err := client.GetDestination(ctx, "src", "webhooks")
+	require.NoError(t, err)
+	assert.False(t, dest.Enabled)
+	assert.Equal(t, "https://example.com", dest.Configs[0].Value)
+
+	filter, err := client.CreateDestinationFilter(ctx, "src", "webhooks", segment.DestinationFilter{
+		Title:      "Drop",
+		Conditions: "event = \"foo\"",
+		Actions:    segment.DestinationFilterActions{segment.NewDropEventAction()},
+	})
+	require.NoError(t, err)
+	filter.Title = "Drop foo"
+	_, err = client.UpdateDestinationFilter(ctx, "src", "webhooks", *filter)
+	require.NoError(t, err)
+	filters, err := client.ListDestinationFilters(ctx, "src", "webhooks")
+	require.NoError(t, err)
+	require.Len(t, filters, 1)
+	assert.Equal(t, "Drop foo", filters[0].Title)
+	assert.Equal(t, segment.DestinationFilterActionTypeDropEvent, filters[0].Actions[0].ActionType())
+	require.NoError(t, client.DeleteDestinationFilter(ctx, "src", "webhooks", utils.PathToName(filter.Name)))
+
+	require.NoError(t, client.DeleteDestination(ctx, "src", "webhooks"))
+	require.NoError(t, client.DeleteSource(ctx, "src"))
+	_, err = client.GetSource(ctx, "src")
+	assertAPIError(t, err, http.StatusNotFound)
+}
+
+func TestServer_PaginatesSources(t *testing.T) {
+	ctx := context.Background()
+	server, client := newClient(t)
+	server.PageSize = 2
+
+	for i := 0; i < 5; i++ {
+		_, err := server.Workspace.CreateSource(ctx, fmt.Sprintf("src-%d", i), "catalog/sources/javascript")
+		require.NoError(t, err)
+	}
+
+	sources, err := client.ListSources(ctx)
+	require.NoError(t, err)
+	require.Len(t, sources, 5)
+	for i, s := range sources {
+		assert.Equal(t, fmt.Sprintf("workspaces/ws/sources/src-%d", i), s.Name)
+	}
+	assert.Equal(t, 3, server.Workspace.CallCount("ListSources"), "every page should be requested")
+}
+
+func TestServer_Catalog(t *testing.T) {
+	ctx := context.Background()
+	server, client := newClient(t)
+	server.Workspace.AddCatalogEntry(configapi.CatalogEntry{Name: "catalog/sources/javascript", DisplayName: "Javascript"})
+	server.Workspace.AddCatalogEntry(configapi.CatalogEntry{
+		Name:     "catalog/destinations/amplitude",
+		Settings: []configapi.CatalogSetting{{Name: "trackAllPages", Type: "boolean", Default: json.RawMessage("false")}},
+	})
+
+	src, err := client.GetCatalogSource(ctx, "javascript")
+	require.NoError(t, err)
+	assert.Equal(t, "Javascript", src.DisplayName)
+	dest, err := client.GetCatalogDestination(ctx, "amplitude")
+	require.NoError(t, err)
+	require.Len(t, dest.Settings, 1)
+	assert.JSONEq(t, "false", string(dest.Settings[0].Default))
+
+	_, err = client.GetCatalogDestination(ctx, "javascript")
+	assertAPIError(t, err, http.StatusNotFound)
+}
+
+func TestServer_TrackingPlans(t *testing.T) {
+	ctx := context.Background()
+	_, client := newClient(t)
+
+	_, err := client.CreateSource(ctx, "src", "catalog/sources/javascript")
+	require.NoError(t, err)
+	tp, err := client.CreateTrackingPlan(ctx, segment.TrackingPlan{DisplayName: "Plan"})
+	require.NoError(t, err)
+	tpID := utils.PathToName(tp.Name)
+
+	_, err = client.UpdateTrackingPlan(ctx, tpID, segment.TrackingPlan{DisplayName: "Renamed"})
+	require.NoError(t, err)
+	tp, err = client.GetTrackingPlan(ctx, tpID)
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", tp.DisplayName)
+
+	require.NoError(t, client.CreateTrackingPlanSourceConnection(ctx, tpID, "src"))
+	connections, err := client.ListTrackingPlanSources(ctx, tpID)
+	require.NoError(t, err)
+	require.Len(t, connections, 1)
+	assert.Equal(t, "workspaces/ws/sources/src", connections[0].Source)
+	require.NoError(t, client.DeleteTrackingPlanSourceConnection(ctx, tpID, "src"))
+
+	require.NoError(t, client.DeleteTrackingPlan(ctx, tpID))
+	tps, err := client.ListTrackingPlans(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, tps.TrackingPlans)
+}
+
+func TestServer_Faults(t *testing.T) {
+	ctx := context.Background()
+	server, client := newClient(t)
+
+	server.InjectFault(fake.Fault{Method: "ListSources", Code: http.StatusTooManyRequests, Times: 1})
+	server.InjectFault(fake.Fault{Method: "ListSources", Code: http.StatusBadGateway, Times: 1})
+
+	_, err := client.ListSources(ctx)
+	assertAPIError(t, err, http.StatusTooManyRequests)
+	_, err = client.ListSources(ctx)
+	assertAPIError(t, err, http.StatusBadGateway)
+	_, err = client.ListSources(ctx)
+	assert.NoError(t, err)
+}
+
+func TestServer_Unauthorized(t *testing.T) {
+	server, _ := newClient(t)
+	client := configapi.NewClient("wrong", "ws", configapi.WithBaseURL(server.URL))
+
+	_, err := client.ListSources(context.Background())
+
+	assertAPIError(t, err, http.StatusUnauthorized)
+}
+
+func assertAPIError(t *testing.T, err error, code int) {
+	t.Helper()
+
+	var apiErr *segment.SegmentApiError
+	if assert.True(t, errors.As(err, &apiErr), "expected a Segment API error, got %v", err) {
+		assert.Equal(t, code, apiErr.Code)
+	}
+}
@@ -0,0 +1,149 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/uswitch/segment-config-go/segment"
+)
+
+// destinationConfigKeyPattern extracts the key of a destination config from the name of the config, e.g.
+// workspaces/myworkspace/sources/mysource/destinations/mydestination/config/apiKey => apiKey
+var destinationConfigKeyPattern = regexp.MustCompile(`/destinations/[^/\s]+/config/([^/\s"',:]+)`)
+
+// apiErrorDiag translates an error returned by the Config API into a diagnostic explaining what went wrong and how to
+// fix it. The action describes what the provider was doing, e.g. "create source foo", and the path points at the
+// attribute involved, if any.
+func apiErrorDiag(err error, action string, path cty.Path) diag.Diagnostics {
+	var readOnlyErr *readOnlyError
+	if errors.As(err, &readOnlyErr) {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "Read-only provider",
+			Detail: fmt.Sprintf("Failed to %s: %s.\n"+
+				"Unset the `read_only` provider argument, or the SEGMENT_READ_ONLY environment variable, to apply changes.", action, err),
+		}}
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "Timeout",
+			Detail: fmt.Sprintf("Failed to %s: %s.\n"+
+				"The operation didn't complete in time, retries included. Raise its timeout with the `timeouts` block of the resource.", action, err),
+			AttributePath: path,
+		}}
+	}
+
+	var apiErr *segment.SegmentApiError
+	if !errors.As(err, &apiErr) {
+		return diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       fmt.Sprintf("Failed to %s", action),
+			Detail:        err.Error(),
+			AttributePath: path,
+		}}
+	}
+
+	d := diag.Diagnostic{
+		Severity:      diag.Error,
+		AttributePath: path,
+	}
+
+	// Wrapped or aggregated errors carry more context than the API error alone
+	message := apiErr.Message
+	if err != error(apiErr) {
+		message = err.Error()
+	}
+
+	switch {
+	case apiErr.Code == http.StatusUnauthorized:
+		d.Summary = "Invalid Segment access token"
+		d.Detail = fmt.Sprintf("Segment rejected the access token when trying to %s: %s.\n"+
+			"Check that the provider reads a valid Config API token from the output of `access_token_command` (SEGMENT_ACCESS_TOKEN_COMMAND), "+
+			"the file at `access_token_file` (SEGMENT_ACCESS_TOKEN_FILE) or `access_token` (SEGMENT_ACCESS_TOKEN), in this order of precedence.", action, message)
+		// The token is a provider argument, it doesn't belong to the resource
+		d.AttributePath = nil
+	case apiErr.Code == http.StatusForbidden:
+		d.Summary = "Missing Segment access token scope"
+		d.Detail = fmt.Sprintf("The access token is not allowed to %s: %s.\n"+
+			"Grant the token access to this resource and workspace in the Access Management settings of Segment.", action, message)
+	case apiErr.Code == http.StatusNotFound:
+		d.Summary = "Segment resource not found"
+		d.Detail = fmt.Sprintf("Failed to %s: %s.", action, message)
+	case apiErr.Code == http.StatusTooManyRequests:
+		d.Summary = "Segment rate limit exceeded"
+		d.Detail = fmt.Sprintf("Failed to %s: %s.\n"+
+			"Lower the `requests_per_second` provider argument or Terraform's parallelism, or raise `max_retries`.", action, message)
+	case apiErr.Code >= http.StatusInternalServerError:
+		d.Summary = "Segment Config API error"
+		d.Detail = fmt.Sprintf("Failed to %s: %s (HTTP %d).\nThis is usually temporary, try again later.", action, message, apiErr.Code)
+	default:
+		// 400s, whose code is taken from the error body
+		d.Summary = "Segment rejected the request"
+		d.Detail = fmt.Sprintf("Failed to %s: %s.", action, message)
+	}
+
+	return diag.Diagnostics{d}
+}
+
+// parentPathIfNotFound returns the path of the attribute referencing the parent of a resource for not found errors, as
+// the parent is missing, and the given path otherwise
+func parentPathIfNotFound(err error, parent cty.Path, path cty.Path) cty.Path {
+	var apiErr *segment.SegmentApiError
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+		return parent
+	}
+
+	return path
+}
+
+// catalogErrorDiag explains a failure to create a source, pointing at the catalog when it doesn't exist
+func catalogErrorDiag(err error, action string, catalog string) diag.Diagnostics {
+	var apiErr *segment.SegmentApiError
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "Unknown catalog",
+			Detail: fmt.Sprintf("Failed to %s: %s is not in the Segment catalog (%s).\n"+
+				"Catalog names look like `catalog/sources/javascript`, see the Segment catalog for the available ones.", action, catalog, apiErr.Message),
+			AttributePath: cty.GetAttrPath(keyCatalog),
+		}}
+	}
+
+	return apiErrorDiag(err, action, cty.GetAttrPath(keyCatalog))
+}
+
+// destinationConfigErrorDiag explains a failure to create or update a destination, pointing at the config key rejected
+// by Segment, if any
+func destinationConfigErrorDiag(err error, action string, config map[string]interface{}) diag.Diagnostics {
+	var apiErr *segment.SegmentApiError
+	if !errors.As(err, &apiErr) {
+		return apiErrorDiag(err, action, cty.GetAttrPath(keyDestConfig))
+	}
+
+	switch apiErr.Code {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests:
+		return apiErrorDiag(err, action, nil)
+	case http.StatusNotFound:
+		return apiErrorDiag(err, action, cty.GetAttrPath(keyDestSource))
+	}
+
+	if match := destinationConfigKeyPattern.FindStringSubmatch(apiErr.Message); match != nil && apiErr.Code < http.StatusInternalServerError {
+		if _, ok := config[match[1]]; ok {
+			return diag.Diagnostics{{
+				Severity:      diag.Error,
+				Summary:       "Invalid destination config",
+				Detail:        fmt.Sprintf("Segment rejected the config key %s when trying to %s: %s.", match[1], action, apiErr.Message),
+				AttributePath: cty.GetAttrPath(keyDestConfig).IndexString(match[1]),
+			}}
+		}
+	}
+
+	return apiErrorDiag(err, action, cty.GetAttrPath(keyDestConfig))
+}
@@ -0,0 +1,185 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/uswitch/terraform-provider-segment/internal/configapi"
+	"github.com/uswitch/terraform-provider-segment/internal/utils"
+)
+
+const (
+	keyCatalogName            = "name"
+	keyCatalogCatalogName     = "catalog_name"
+	keyCatalogDisplayName     = "display_name"
+	keyCatalogDescription     = "description"
+	keyCatalogCategories      = "categories"
+	keyCatalogConnectionModes = "connection_modes"
+	keyCatalogSettings        = "settings"
+	keyCatalogSettingType     = "type"
+	keyCatalogSettingRequired = "required"
+	keyCatalogSettingDefault  = "default"
+)
+
+func dataSourceSegmentCatalogSource() *schema.Resource {
+	return dataSourceCatalog(catalogKind{
+		kind:                   "source",
+		example:                "javascript",
+		prefix:                 configapi.CatalogSourcePrefix,
+		catalogNameDescription: "The catalog name of the source, to set as the `catalog_name` of `segment_source`.",
+		get:                    SegmentAPI.GetCatalogSource,
+	})
+}
+
+func dataSourceSegmentCatalogDestination() *schema.Resource {
+	return dataSourceCatalog(catalogKind{
+		kind:                   "destination",
+		example:                "amplitude",
+		prefix:                 configapi.CatalogDestinationPrefix,
+		catalogNameDescription: "The catalog name of the destination, e.g. `catalog/destinations/amplitude`.",
+		get:                    SegmentAPI.GetCatalogDestination,
+	})
+}
+
+// catalogKind describes the entries of the catalog of either sources or destinations
+type catalogKind struct {
+	kind                   string // e.g. `source`
+	example                string
+	prefix                 string
+	catalogNameDescription string
+	get                    func(client SegmentAPI, ctx context.Context, name string) (configapi.CatalogEntry, error)
+}
+
+// dataSourceCatalog creates the data source of a kind of catalog entries
+func dataSourceCatalog(c catalogKind) *schema.Resource {
+	kind := c.kind
+
+	return &schema.Resource{
+		Description: fmt.Sprintf("A type of %s of the Segment catalog, e.g. to check its name and settings while planning rather than when applying.", kind),
+		ReadContext: operation("read data source segment_catalog_"+kind, func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			return dataSourceCatalogRead(ctx, d, m, c)
+		}),
+		Schema: map[string]*schema.Schema{
+			keyCatalogName: {
+				Description:  fmt.Sprintf("The name of the %s in the catalog, e.g. `%s`. The `%s` prefix of catalog names is optional.", kind, c.example, c.prefix),
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			keyCatalogCatalogName: {
+				Description: c.catalogNameDescription,
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			keyCatalogDisplayName: {
+				Description: fmt.Sprintf("The name of the %s in the Segment UI.", kind),
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			keyCatalogDescription: {
+				Description: fmt.Sprintf("The description of the %s.", kind),
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			keyCatalogCategories: {
+				Description: fmt.Sprintf("The categories of the %s, the primary one first, e.g. `Analytics`.", kind),
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			keyCatalogConnectionModes: {
+				Description: "The connection modes supported on at least one platform, web, mobile or server: `CLOUD` and `DEVICE`.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			keyCatalogSettings: {
+				Description: fmt.Sprintf("The settings of the %s.", kind),
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						keyCatalogName: {
+							Description: "The name of the setting.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						keyCatalogDisplayName: {
+							Description: "The name of the setting in the Segment UI.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						keyCatalogDescription: {
+							Description: "The description of the setting.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						keyCatalogSettingType: {
+							Description: "The type of the value of the setting, e.g. `string`, `boolean` or `map`.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						keyCatalogSettingRequired: {
+							Description: "Whether the setting is required.",
+							Type:        schema.TypeBool,
+							Computed:    true,
+						},
+						keyCatalogSettingDefault: {
+							Description: "The JSON encoded value the setting defaults to, empty if it has no default. Use `jsondecode()` to read it.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+		Timeouts: dataSourceTimeouts(),
+	}
+}
+
+func dataSourceCatalogRead(ctx context.Context, d *schema.ResourceData, m interface{}, c catalogKind) diag.Diagnostics {
+	meta := resourceMeta(m, d)
+	name := strings.TrimPrefix(d.Get(keyCatalogName).(string), c.prefix)
+
+	entry, err := c.get(meta.Client, ctx, name)
+	if err != nil {
+		return apiErrorDiag(err, fmt.Sprintf("read %s %s from the catalog", c.kind, name), cty.GetAttrPath(keyCatalogName))
+	}
+
+	d.SetId(entry.Name)
+
+	return utils.CatchFirst(
+		func() error { return d.Set(keyCatalogName, name) },
+		func() error { return d.Set(keyCatalogCatalogName, entry.Name) },
+		func() error { return d.Set(keyCatalogDisplayName, entry.DisplayName) },
+		func() error { return d.Set(keyCatalogDescription, entry.Description) },
+		func() error { return d.Set(keyCatalogCategories, entry.Categories.All()) },
+		func() error { return d.Set(keyCatalogConnectionModes, entry.ConnectionModes.Supported()) },
+		func() error { return d.Set(keyCatalogSettings, encodeCatalogSettings(entry.Settings)) },
+	)
+}
+
+func encodeCatalogSettings(settings []configapi.CatalogSetting) []interface{} {
+	encoded := make([]interface{}, 0, len(settings))
+	for _, s := range settings {
+		encoded = append(encoded, map[string]interface{}{
+			keyCatalogName:            s.Name,
+			keyCatalogDisplayName:     s.DisplayName,
+			keyCatalogDescription:     s.Description,
+			keyCatalogSettingType:     s.Type,
+			keyCatalogSettingRequired: s.Required,
+			keyCatalogSettingDefault:  string(s.Default),
+		})
+	}
+
+	return encoded
+}
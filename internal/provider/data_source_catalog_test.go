@@ -0,0 +1,97 @@
+package provider_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uswitch/terraform-provider-segment/internal/configapi"
+	"github.com/uswitch/terraform-provider-segment/internal/fake"
+)
+
+func newWorkspaceWithCatalog() *fake.Workspace {
+	ws := fake.NewWorkspace(unitTestWorkspace)
+	ws.AddCatalogEntry(configapi.CatalogEntry{
+		Name:            "catalog/sources/javascript",
+		DisplayName:     "Javascript",
+		Description:     "Track events from websites",
+		Categories:      configapi.CatalogCategories{Primary: "Website"},
+		ConnectionModes: configapi.ConnectionModes{Device: configapi.ConnectionModePlatforms{Web: true}},
+	})
+	ws.AddCatalogEntry(configapi.CatalogEntry{
+		Name:        "catalog/destinations/amplitude",
+		DisplayName: "Amplitude",
+		Categories:  configapi.CatalogCategories{Primary: "Analytics", Additional: []string{"Attribution"}},
+		ConnectionModes: configapi.ConnectionModes{
+			Device: configapi.ConnectionModePlatforms{Web: true},
+			Cloud:  configapi.ConnectionModePlatforms{Web: true, Mobile: true, Server: true},
+		},
+		Settings: []configapi.CatalogSetting{
+			{Name: "apiKey", DisplayName: "API Key", Type: "string", Required: true},
+			{Name: "trackAllPages", DisplayName: "Track All Pages", Type: "boolean", Default: json.RawMessage("false")},
+		},
+	})
+
+	return ws
+}
+
+func TestDataSourceCatalogSource_read(t *testing.T) {
+	ws := newWorkspaceWithCatalog()
+
+	for _, name := range []string{"javascript", "catalog/sources/javascript"} {
+		d, diags := readDataSource(t, "segment_catalog_source", unitTestMeta(ws), map[string]interface{}{"name": name})
+
+		require.Empty(t, diags)
+		assert.Equal(t, "catalog/sources/javascript", d.Id())
+		assert.Equal(t, "javascript", d.Get("name"))
+		assert.Equal(t, "catalog/sources/javascript", d.Get("catalog_name"))
+		assert.Equal(t, "Javascript", d.Get("display_name"))
+		assert.Equal(t, "Track events from websites", d.Get("description"))
+		assert.Equal(t, []interface{}{"Website"}, d.Get("categories"))
+		assert.Equal(t, []interface{}{"DEVICE"}, d.Get("connection_modes"))
+		assert.Empty(t, d.Get("settings"))
+	}
+}
+
+func TestDataSourceCatalogDestination_read(t *testing.T) {
+	ws := newWorkspaceWithCatalog()
+
+	d, diags := readDataSource(t, "segment_catalog_destination", unitTestMeta(ws), map[string]interface{}{"name": "amplitude"})
+
+	require.Empty(t, diags)
+	assert.Equal(t, "catalog/destinations/amplitude", d.Get("catalog_name"))
+	assert.Equal(t, []interface{}{"Analytics", "Attribution"}, d.Get("categories"))
+	assert.Equal(t, []interface{}{"CLOUD", "DEVICE"}, d.Get("connection_modes"))
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"name":         "apiKey",
+			"display_name": "API Key",
+			"description":  "",
+			"type":         "string",
+			"required":     true,
+			"default":      "",
+		},
+		map[string]interface{}{
+			"name":         "trackAllPages",
+			"display_name": "Track All Pages",
+			"description":  "",
+			"type":         "boolean",
+			"required":     false,
+			"default":      "false",
+		},
+	}, d.Get("settings"))
+}
+
+func TestDataSourceCatalog_unknownEntry(t *testing.T) {
+	ws := newWorkspaceWithCatalog()
+
+	// Sources and destinations are separate catalogs
+	_, diags := readDataSource(t, "segment_catalog_destination", unitTestMeta(ws), map[string]interface{}{"name": "javascript"})
+
+	require.Len(t, diags, 1)
+	assert.Equal(t, "Segment resource not found", diags[0].Summary)
+	assert.Contains(t, diags[0].Detail, "read destination javascript from the catalog")
+	assert.Equal(t, cty.GetAttrPath("name"), diags[0].AttributePath)
+}
@@ -50,7 +50,9 @@ func dataSourceEventLibraryRead(_ context.Context, d *schema.ResourceData, _ int
 	}
 	jsonString := string(outputJSON)
 
-	d.Set("json", jsonString)
+	if err := d.Set("json", jsonString); err != nil {
+		return diag.FromErr(err)
+	}
 	d.SetId(strconv.Itoa(hashcode.String(jsonString)))
 
 	return diags
@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/uswitch/terraform-provider-segment/internal/utils"
+)
+
+func dataSourceSegmentSource() *schema.Resource {
+	return &schema.Resource{
+		Description: "An existing source of the workspace, e.g. one managed outside of Terraform, to connect destinations and filters to.",
+		ReadContext: operation("read data source segment_source", dataSourceSegmentSourceRead),
+		Schema: map[string]*schema.Schema{
+			keyWorkspace: dataSourceWorkspaceSchema("The slug of the workspace the source belongs to. Defaults to the `workspace` of the provider."),
+			keySource: {
+				Description: "The name of the source.",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			keyCatalog: {
+				Description: "The catalog name of the source.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			keySourceDisplayName: {
+				Description: "The name of the source in the Segment UI.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			keySourceEnabled: {
+				Description: "Whether the source is enabled.",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
+			keyTrackingPlan: {
+				Description: "The ID of the tracking plan connected to the source, if any.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			keySchemaConfig: {
+				Description: "The configuration of the source's events.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Resource{Schema: computedSchemaConfig()},
+			},
+			keyWriteKeys: {
+				Description: "The write keys of the source, which clients send events with.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Sensitive:   true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+		Timeouts: dataSourceTimeouts(),
+	}
+}
+
+func dataSourceSegmentSourceRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := resourceMeta(m, d)
+	client := meta.Client
+	srcName := d.Get(keySource).(string)
+
+	s, err := client.GetSource(ctx, srcName)
+	if err != nil {
+		return apiErrorDiag(err, "read source "+srcName, cty.GetAttrPath(keySource))
+	}
+
+	tpID, diags := findTrackingPlanSourceConnection(ctx, srcName, meta)
+	if diags != nil {
+		return *diags
+	}
+
+	config, err := client.GetSourceConfig(ctx, srcName)
+	if err != nil {
+		return apiErrorDiag(err, "read the schema config of source "+srcName, cty.GetAttrPath(keySchemaConfig))
+	}
+
+	d.SetId(srcName)
+
+	return utils.CatchFirst(
+		func() error { return d.Set(keyCatalog, s.CatalogName) },
+		func() error {
+			if s.DisplayName == nil {
+				return nil
+			}
+			return d.Set(keySourceDisplayName, *s.DisplayName)
+		},
+		func() error {
+			if s.Enabled == nil {
+				return nil
+			}
+			return d.Set(keySourceEnabled, *s.Enabled)
+		},
+		func() error { return d.Set(keyTrackingPlan, tpID) },
+		func() error { return d.Set(keySchemaConfig, encodeSourceConfig(config)) },
+		func() error { return d.Set(keyWriteKeys, s.WriteKeys) },
+	)
+}
+
+// computedSchemaConfig returns the attributes of the schema config of segment_source, all computed
+func computedSchemaConfig() map[string]*schema.Schema {
+	attributes := resourceSegmentSource().Schema[keySchemaConfig].Elem.(*schema.Resource).Schema
+
+	computed := make(map[string]*schema.Schema, len(attributes))
+	for key, attribute := range attributes {
+		computed[key] = &schema.Schema{
+			Description: attribute.Description,
+			Type:        attribute.Type,
+			Computed:    true,
+		}
+	}
+
+	return computed
+}
@@ -0,0 +1,68 @@
+package provider_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uswitch/segment-config-go/segment"
+	"github.com/uswitch/terraform-provider-segment/internal/fake"
+	"github.com/uswitch/terraform-provider-segment/internal/provider"
+)
+
+func TestDataSourceSource_read(t *testing.T) {
+	ctx := context.Background()
+	ws, src, tpID := newWorkspaceWithTrackingPlans(t, 2)
+	require.NoError(t, ws.EditSource(src, "Mobile app", false))
+	config, err := ws.GetSourceConfig(ctx, src)
+	require.NoError(t, err)
+	config.CommonTrackEventOnViolations = segment.Block
+	_, err = ws.UpdateSourceConfig(ctx, src, config)
+	require.NoError(t, err)
+	created, err := ws.GetSource(ctx, src)
+	require.NoError(t, err)
+
+	d, diags := readDataSource(t, "segment_source", unitTestMeta(ws), map[string]interface{}{"source_name": src})
+
+	require.Empty(t, diags)
+	assert.Equal(t, src, d.Id())
+	assert.Equal(t, "catalog/sources/javascript", d.Get("catalog_name"))
+	assert.Equal(t, "Mobile app", d.Get("display_name"))
+	assert.Equal(t, false, d.Get("enabled"))
+	assert.Equal(t, tpID, d.Get("tracking_plan"))
+	assert.Equal(t, "BLOCK", d.Get("schema_config.0.common_track_event_on_violations"))
+	assert.Equal(t, true, d.Get("schema_config.0.allow_unplanned_track_events"))
+	assert.Equal(t, []interface{}{created.WriteKeys[0]}, d.Get("write_keys"))
+}
+
+func TestDataSourceSource_withoutTrackingPlan(t *testing.T) {
+	ctx := context.Background()
+	ws := fake.NewWorkspace(unitTestWorkspace)
+	_, err := ws.CreateSource(ctx, "my-source", "catalog/sources/ios")
+	require.NoError(t, err)
+
+	d, diags := readDataSource(t, "segment_source", unitTestMeta(ws), map[string]interface{}{"source_name": "my-source"})
+
+	require.Empty(t, diags)
+	assert.Equal(t, "", d.Get("tracking_plan"))
+	assert.Equal(t, "my-source", d.Get("display_name"))
+	assert.Equal(t, "ALLOW", d.Get("schema_config.0.common_track_event_on_violations"))
+}
+
+func TestDataSourceSource_notFound(t *testing.T) {
+	ws := fake.NewWorkspace(unitTestWorkspace)
+
+	_, diags := readDataSource(t, "segment_source", unitTestMeta(ws), map[string]interface{}{"source_name": "does-not-exist"})
+
+	require.Len(t, diags, 1)
+	assert.Equal(t, cty.GetAttrPath("source_name"), diags[0].AttributePath)
+	assert.Contains(t, diags[0].Detail, "does-not-exist")
+}
+
+func TestDataSourceSource_writeKeysAreSensitive(t *testing.T) {
+	writeKeys := provider.New().DataSourcesMap["segment_source"].Schema["write_keys"]
+
+	assert.True(t, writeKeys.Sensitive)
+}
@@ -0,0 +1,128 @@
+package provider
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/uswitch/terraform-provider-segment/internal/hashcode"
+	"github.com/uswitch/terraform-provider-segment/internal/utils"
+)
+
+const (
+	keySourcesNamePrefix = "name_prefix"
+	keySources           = "sources"
+)
+
+func dataSourceSegmentSources() *schema.Resource {
+	return &schema.Resource{
+		Description: "The sources of the workspace, optionally filtered by name, catalog or tracking plan.",
+		ReadContext: operation("read data source segment_sources", dataSourceSegmentSourcesRead),
+		Schema: map[string]*schema.Schema{
+			keyWorkspace: dataSourceWorkspaceSchema("The slug of the workspace to list the sources of. Defaults to the `workspace` of the provider."),
+			keySourcesNamePrefix: {
+				Description: "Only list the sources whose name starts with this prefix.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			keyCatalog: {
+				Description: "Only list the sources of this catalog name, e.g. `catalog/sources/javascript`.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			keyTrackingPlan: {
+				Description: "Only list the sources connected to the tracking plan with this ID.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			keySources: {
+				Description: "The matching sources, in the order of the Config API.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						keySource: {
+							Description: "The name of the source.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						keyCatalog: {
+							Description: "The catalog name of the source.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						keySourceDisplayName: {
+							Description: "The name of the source in the Segment UI.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						keySourceEnabled: {
+							Description: "Whether the source is enabled.",
+							Type:        schema.TypeBool,
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+		Timeouts: dataSourceTimeouts(),
+	}
+}
+
+func dataSourceSegmentSourcesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := resourceMeta(m, d)
+	client := meta.Client
+	prefix := d.Get(keySourcesNamePrefix).(string)
+	catName := d.Get(keyCatalog).(string)
+	tpID := d.Get(keyTrackingPlan).(string)
+
+	var connected map[string]bool
+	if tpID != "" {
+		connections, err := client.ListTrackingPlanSources(ctx, tpID)
+		if err != nil {
+			return protocolsErrorDiag(err, "list the sources of tracking plan "+tpID, cty.GetAttrPath(keyTrackingPlan))
+		}
+		connected = map[string]bool{}
+		for _, c := range connections {
+			connected[utils.PathToName(c.Source)] = true
+		}
+	}
+
+	all, err := client.ListSources(ctx)
+	if err != nil {
+		return apiErrorDiag(err, "list sources", nil)
+	}
+
+	sources := []interface{}{}
+	for _, s := range all {
+		srcName := utils.PathToName(s.Name)
+		if !strings.HasPrefix(srcName, prefix) ||
+			(catName != "" && s.CatalogName != catName) ||
+			(connected != nil && !connected[srcName]) {
+			continue
+		}
+
+		source := map[string]interface{}{
+			keySource:  srcName,
+			keyCatalog: s.CatalogName,
+		}
+		if s.DisplayName != nil {
+			source[keySourceDisplayName] = *s.DisplayName
+		}
+		if s.Enabled != nil {
+			source[keySourceEnabled] = *s.Enabled
+		}
+		sources = append(sources, source)
+	}
+	tflog.Debug(ctx, "Listed sources", map[string]interface{}{"total": len(all), "matching": len(sources)})
+
+	d.SetId(strconv.Itoa(hashcode.String(strings.Join([]string{meta.Workspace, prefix, catName, tpID}, "\n"))))
+
+	return utils.CatchFirst(
+		func() error { return d.Set(keySources, sources) },
+	)
+}
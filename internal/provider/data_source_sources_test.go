@@ -0,0 +1,110 @@
+package provider_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uswitch/segment-config-go/segment"
+	"github.com/uswitch/terraform-provider-segment/internal/fake"
+	"github.com/uswitch/terraform-provider-segment/internal/mockapi"
+	"github.com/uswitch/terraform-provider-segment/internal/utils"
+)
+
+// sourceNames returns the names of the sources listed by segment_sources
+func sourceNames(sources interface{}) []string {
+	names := []string{}
+	for _, s := range sources.([]interface{}) {
+		names = append(names, s.(map[string]interface{})["source_name"].(string))
+	}
+
+	return names
+}
+
+func TestDataSourceSources_filters(t *testing.T) {
+	ctx := context.Background()
+	ws := fake.NewWorkspace(unitTestWorkspace)
+	tp, err := ws.CreateTrackingPlan(ctx, segment.TrackingPlan{DisplayName: "Plan"})
+	require.NoError(t, err)
+	tpID := utils.PathToName(tp.Name)
+	for src, catName := range map[string]string{
+		"android-app": "catalog/sources/android",
+		"ios-app":     "catalog/sources/ios",
+		"web-app":     "catalog/sources/javascript",
+		"web-site":    "catalog/sources/javascript",
+	} {
+		_, err := ws.CreateSource(ctx, src, catName)
+		require.NoError(t, err)
+	}
+	require.NoError(t, ws.CreateTrackingPlanSourceConnection(ctx, tpID, "ios-app"))
+	require.NoError(t, ws.CreateTrackingPlanSourceConnection(ctx, tpID, "web-app"))
+
+	tests := []struct {
+		name     string
+		config   map[string]interface{}
+		expected []string
+	}{
+		{"all", map[string]interface{}{}, []string{"android-app", "ios-app", "web-app", "web-site"}},
+		{"name prefix", map[string]interface{}{"name_prefix": "web-"}, []string{"web-app", "web-site"}},
+		{"catalog", map[string]interface{}{"catalog_name": "catalog/sources/ios"}, []string{"ios-app"}},
+		{"tracking plan", map[string]interface{}{"tracking_plan": tpID}, []string{"ios-app", "web-app"}},
+		{"combined", map[string]interface{}{"name_prefix": "web-", "tracking_plan": tpID}, []string{"web-app"}},
+		{"no match", map[string]interface{}{"catalog_name": "catalog/sources/ruby"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, diags := readDataSource(t, "segment_sources", unitTestMeta(ws), tt.config)
+
+			require.Empty(t, diags)
+			assert.Equal(t, tt.expected, sourceNames(d.Get("sources")))
+			assert.NotEmpty(t, d.Id())
+		})
+	}
+}
+
+func TestDataSourceSources_attributes(t *testing.T) {
+	ctx := context.Background()
+	ws := fake.NewWorkspace(unitTestWorkspace)
+	_, err := ws.CreateSource(ctx, "web-app", "catalog/sources/javascript")
+	require.NoError(t, err)
+	require.NoError(t, ws.EditSource("web-app", "Web app", false))
+
+	d, diags := readDataSource(t, "segment_sources", unitTestMeta(ws), map[string]interface{}{})
+
+	require.Empty(t, diags)
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"source_name":  "web-app",
+		"catalog_name": "catalog/sources/javascript",
+		"display_name": "Web app",
+		"enabled":      false,
+	}}, d.Get("sources"))
+}
+
+func TestDataSourceSources_unknownTrackingPlan(t *testing.T) {
+	ws := fake.NewWorkspace(unitTestWorkspace)
+
+	_, diags := readDataSource(t, "segment_sources", unitTestMeta(ws), map[string]interface{}{"tracking_plan": "rs_does_not_exist"})
+
+	require.True(t, diags.HasError())
+	assert.Zero(t, ws.CallCount("ListSources"))
+}
+
+func TestDataSourceSources_followsPagination(t *testing.T) {
+	ctx := context.Background()
+	server := mockapi.NewServer(unitTestWorkspace, "token")
+	defer server.Close()
+	server.PageSize = 2
+	for i := 0; i < 5; i++ {
+		_, err := server.Workspace.CreateSource(ctx, fmt.Sprintf("source-%d", i), "catalog/sources/javascript")
+		require.NoError(t, err)
+	}
+	meta := configureProvider(t, server, nil)
+
+	d, diags := readDataSource(t, "segment_sources", meta, map[string]interface{}{"name_prefix": "source-"})
+
+	require.Empty(t, diags)
+	assert.Equal(t, []string{"source-0", "source-1", "source-2", "source-3", "source-4"}, sourceNames(d.Get("sources")))
+	assert.Equal(t, 3, server.Workspace.CallCount("ListSources"))
+}
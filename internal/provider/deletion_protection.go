@@ -0,0 +1,74 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const keyDeletionProtection = "deletion_protection"
+
+// withDeletionProtection adds the `deletion_protection` attribute to a resource, and refuses plans replacing the
+// resource while it is protected. Its delete function must refuse to delete it too, see refuseProtectedDeletion.
+// Example:
+// 	func resourceSegmentSource() *schema.Resource {
+// 		return withDeletionProtection("source", &schema.Resource{...})
+// 	}
+func withDeletionProtection(resource string, r *schema.Resource) *schema.Resource {
+	r.Schema[keyDeletionProtection] = &schema.Schema{
+		Description: fmt.Sprintf("Whether Terraform is prevented from destroying the %s, including to replace it. "+
+			"It must be set to `false`, and applied, before the %s can be destroyed.", resource, resource),
+		Type:     schema.TypeBool,
+		Optional: true,
+		Default:  false,
+	}
+
+	var forceNew []string
+	for key, s := range r.Schema {
+		if s.ForceNew {
+			forceNew = append(forceNew, key)
+		}
+	}
+	sort.Strings(forceNew)
+
+	r.CustomizeDiff = func(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+		// The protection applies as it is in the state, so it can't be lifted by the plan replacing the resource
+		if protected, _ := d.GetChange(keyDeletionProtection); d.Id() == "" || !protected.(bool) {
+			return nil
+		}
+
+		for _, key := range forceNew {
+			if d.HasChange(key) {
+				return fmt.Errorf("refusing to replace %s %s as its %s is true: changing %s requires a new %s.\n"+
+					"Set %s to false, and apply, before changing %s", resource, d.Id(), keyDeletionProtection, key, resource, keyDeletionProtection, key)
+			}
+		}
+
+		return nil
+	}
+
+	if r.Importer != nil && r.Importer.StateContext != nil {
+		r.Importer.StateContext = importWithDefaults(r.Importer.StateContext, map[string]interface{}{keyDeletionProtection: false})
+	}
+
+	return r
+}
+
+// refuseProtectedDeletion returns an error diagnostic if the resource has deletion protection enabled, nil otherwise
+func refuseProtectedDeletion(r *schema.ResourceData, action string) diag.Diagnostics {
+	if !r.Get(keyDeletionProtection).(bool) {
+		return nil
+	}
+
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  "Deletion protection enabled",
+		Detail: fmt.Sprintf("Refusing to %s as its `%s` is true.\n"+
+			"Set `%s` to false, and apply, before destroying it.", action, keyDeletionProtection, keyDeletionProtection),
+		AttributePath: cty.GetAttrPath(keyDeletionProtection),
+	}}
+}
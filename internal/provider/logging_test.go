@@ -0,0 +1,88 @@
+package provider_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uswitch/terraform-provider-segment/internal/fake"
+	"github.com/uswitch/terraform-provider-segment/internal/utils"
+)
+
+// captureLogs makes the operations of a resource log as JSON to the returned buffer
+func captureLogs(r *unitTestResource) *bytes.Buffer {
+	var logs bytes.Buffer
+	r.ctx = tflogtest.RootLogger(context.Background(), &logs)
+
+	return &logs
+}
+
+// logEntries decodes the captured logs, keeping the ones of a subsystem
+func logEntries(t *testing.T, logs *bytes.Buffer, subsystem string) []map[string]interface{} {
+	entries, err := tflogtest.MultilineJSONDecode(logs)
+	require.NoError(t, err)
+
+	var matching []map[string]interface{}
+	for _, entry := range entries {
+		if entry["@module"] == "provider."+subsystem {
+			matching = append(matching, entry)
+		}
+	}
+
+	return matching
+}
+
+func TestLogging_trackingPlanMerge(t *testing.T) {
+	ws := fake.NewWorkspace(unitTestWorkspace)
+	r := newUnitTestResource(t, "segment_tracking_plan", ws)
+	logs := captureLogs(r)
+
+	require.Empty(t, r.apply(map[string]interface{}{
+		"display_name":    "My Plan",
+		"rules_json_file": testTrackingPlanRules,
+	}))
+
+	entries := logEntries(t, logs, utils.LogTrackingPlanMerge)
+	require.NotEmpty(t, entries)
+	comparison := entries[0]
+	assert.Equal(t, "trace", comparison["@level"])
+	assert.Equal(t, "Order Completed", comparison["event"])
+	assert.Equal(t, false, comparison["library_event"])
+	assert.Equal(t, "create segment_tracking_plan", comparison["operation"])
+	assert.Equal(t, unitTestWorkspace, comparison["workspace"])
+}
+
+func TestLogging_connectionCache(t *testing.T) {
+	ws, src, tpID := newWorkspaceWithTrackingPlans(t, 3)
+	r := newUnitTestResource(t, "segment_source", ws)
+	logs := captureLogs(r)
+
+	require.Empty(t, r.importState(src))
+
+	entries := logEntries(t, logs, utils.LogConnectionCache)
+	require.NotEmpty(t, entries)
+	lookup := entries[len(entries)-1]
+	assert.Equal(t, src, lookup["source"])
+	assert.Equal(t, tpID, lookup["tracking_plan"])
+	assert.Equal(t, true, lookup["hit"])
+	assert.Equal(t, "read segment_source", lookup["operation"])
+	assert.Equal(t, src, lookup["id"])
+}
+
+func TestLogging_subsystemLevelFromEnv(t *testing.T) {
+	t.Setenv("TF_LOG_PROVIDER_TRACKING_PLAN_MERGE", "WARN")
+
+	ws := fake.NewWorkspace(unitTestWorkspace)
+	r := newUnitTestResource(t, "segment_tracking_plan", ws)
+	logs := captureLogs(r)
+
+	require.Empty(t, r.apply(map[string]interface{}{
+		"display_name":    "My Plan",
+		"rules_json_file": testTrackingPlanRules,
+	}))
+
+	assert.Empty(t, logEntries(t, logs, utils.LogTrackingPlanMerge))
+}
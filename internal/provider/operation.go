@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/uswitch/terraform-provider-segment/internal/configapi"
+	"github.com/uswitch/terraform-provider-segment/internal/tracing"
+	"github.com/uswitch/terraform-provider-segment/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer creates a span per operation, parent of the spans of the Config API calls it performs
+var tracer = tracing.Tracer("provider")
+
+// operationFunc is the signature shared by the CRUD functions of resources and data sources
+type operationFunc = func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics
+
+// operation wraps a CRUD function to trace it, to log with the operation, the ID and the workspace of the resource as
+// fields, and to report on the Config API calls it performs. The credentials of the workspace are validated before the
+// first operation on it.
+// Example:
+// 	CreateContext: operation("create segment_source", resourceSegmentSourceCreate),
+func operation(name string, f operationFunc) operationFunc {
+	return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		meta := resourceMeta(m, d)
+		workspace := meta.Workspace
+
+		ctx, span := tracer.Start(ctx, name, trace.WithAttributes(
+			attribute.String("segment.id", d.Id()),
+			attribute.String("segment.workspace", workspace),
+		))
+		defer span.End()
+
+		ctx = utils.WithLogging(ctx, map[string]interface{}{
+			"operation": name,
+			"id":        d.Id(),
+			"workspace": workspace,
+		})
+		ctx = configapi.WithThrottleCounter(ctx)
+
+		diags := meta.Workspaces.validate(ctx, workspace)
+		if !diags.HasError() {
+			diags = f(ctx, d, m)
+		}
+
+		throttled := configapi.ThrottledCount(ctx)
+		tflog.SubsystemDebug(ctx, utils.LogSegmentAPI, "Config API calls throttled by the rate limiter", map[string]interface{}{
+			"throttled": throttled,
+		})
+
+		span.SetAttributes(attribute.Int("segment.throttled", throttled))
+		if id := d.Id(); id != "" {
+			span.SetAttributes(attribute.String("segment.id", id))
+		}
+		for _, diagnostic := range diags {
+			if diagnostic.Severity == diag.Error {
+				span.SetStatus(codes.Error, diagnostic.Summary)
+				break
+			}
+		}
+
+		return diags
+	}
+}
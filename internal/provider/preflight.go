@@ -0,0 +1,74 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/uswitch/segment-config-go/segment"
+)
+
+// validateCredentials checks that the access token is valid for the workspace, and has the scopes needed by the
+// resources, before any resource of the workspace is planned or applied. A typo in the configuration or a missing
+// scope is then reported once instead of in the middle of an apply.
+func validateCredentials(ctx context.Context, client SegmentAPI, workspace string) diag.Diagnostics {
+	tflog.Info(ctx, "Validating the access token", map[string]interface{}{"workspace": workspace})
+
+	if _, err := client.GetWorkspace(ctx); err != nil {
+		return workspaceErrorDiag(err, workspace)
+	}
+
+	if _, err := client.ListTrackingPlans(ctx); err != nil {
+		var apiErr *segment.SegmentApiError
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusForbidden {
+			return diag.Diagnostics{{
+				Severity: diag.Error,
+				Summary:  "Missing Segment Protocols scope",
+				Detail: fmt.Sprintf("The access token is not allowed to list the tracking plans of workspace %s: %s.\n"+
+					"`segment_source` and `segment_tracking_plan` resources look up tracking plans, grant the token access to Protocols in the Access Management settings of Segment. "+
+					"Set `skip_credentials_validation` if the configuration only manages destinations.", workspace, apiErr.Message),
+			}}
+		}
+
+		return apiErrorDiag(err, "list the tracking plans of workspace "+workspace, nil)
+	}
+
+	return nil
+}
+
+// workspaceErrorDiag explains a failure to access the workspace of the provider or of a resource
+func workspaceErrorDiag(err error, workspace string) diag.Diagnostics {
+	var apiErr *segment.SegmentApiError
+	if errors.As(err, &apiErr) && (apiErr.Code == http.StatusForbidden || apiErr.Code == http.StatusNotFound) {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "Unknown Segment workspace",
+			Detail: fmt.Sprintf("The access token can't access workspace %s: %s.\n"+
+				"Check that the `workspace` argument of the provider or of the resource, or the SEGMENT_WORKSPACE environment variable, is the slug of the workspace the token was created in.", workspace, apiErr.Message),
+			AttributePath: cty.GetAttrPath(keyWorkspace),
+		}}
+	}
+
+	return apiErrorDiag(err, "read workspace "+workspace, nil)
+}
+
+// protocolsErrorDiag explains a failure to access tracking plans, which requires the access token to be granted the
+// Protocols scope on top of the ones needed to manage sources and destinations
+func protocolsErrorDiag(err error, action string, path cty.Path) diag.Diagnostics {
+	var apiErr *segment.SegmentApiError
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusForbidden {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "Missing Segment Protocols scope",
+			Detail: fmt.Sprintf("The access token is not allowed to %s: %s.\n"+
+				"`segment_source` and `segment_tracking_plan` resources look up tracking plans, grant the token access to Protocols in the Access Management settings of Segment.", action, err),
+			AttributePath: path,
+		}}
+	}
+
+	return apiErrorDiag(err, action, path)
+}
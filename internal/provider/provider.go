@@ -2,11 +2,22 @@ package provider
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
 
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/logging"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/uswitch/segment-config-go/segment"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/uswitch/terraform-provider-segment/internal/configapi"
+	"github.com/uswitch/terraform-provider-segment/internal/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Provider -
@@ -14,11 +25,24 @@ func New() *schema.Provider {
 	return &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"access_token": {
-				Description: "A Segment Config API token, more details about it can be found the [Config API documentation](https://segment.com/docs/config-api/authentication/).",
+				Description: "A Segment Config API token, more details about it can be found the [Config API documentation](https://segment.com/docs/config-api/authentication/). One of `access_token`, `access_token_file` or `access_token_command` is required.",
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
+				Sensitive:   true,
 				DefaultFunc: schema.EnvDefaultFunc("SEGMENT_ACCESS_TOKEN", nil),
 			},
+			"access_token_file": {
+				Description: "The path to a file holding the Config API token, read every time the provider is configured. Takes precedence over `access_token`.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SEGMENT_ACCESS_TOKEN_FILE", nil),
+			},
+			"access_token_command": {
+				Description: "A command printing the Config API token on its standard output, e.g. the CLI of a secrets manager. The token is cached, and the command is run again when Segment rejects it. Takes precedence over `access_token` and `access_token_file`.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SEGMENT_ACCESS_TOKEN_COMMAND", nil),
+			},
 			"workspace": {
 				Description: "The Segment workspace slug.",
 				Type:        schema.TypeString,
@@ -36,6 +60,93 @@ func New() *schema.Provider {
 				},
 				DefaultFunc: func() (interface{}, error) { return []interface{}{}, nil },
 			},
+			"endpoint": {
+				Description:  "The base URL of the Segment Config API. Defaults to `" + configapi.DefaultBaseURL + "`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("SEGMENT_ENDPOINT", configapi.DefaultBaseURL),
+				ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+			},
+			"http_proxy": {
+				Description:  "The URL of a proxy to send Config API requests through. When not set, the standard `HTTPS_PROXY`/`NO_PROXY` environment variables are honoured.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("SEGMENT_HTTP_PROXY", nil),
+				ValidateFunc: validation.IsURLWithScheme([]string{"http", "https", "socks5"}),
+			},
+			"ca_cert_file": {
+				Description: "The path to a PEM encoded CA bundle to trust in addition to the system certificates, e.g. for a TLS intercepting proxy.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SEGMENT_CA_CERT_FILE", nil),
+			},
+			"request_timeout": {
+				Description:      "The maximum duration of a single Config API request, as a Go duration string (e.g. `30s`, `2m`). `0` disables the timeout. Defaults to `60s`.",
+				Type:             schema.TypeString,
+				Optional:         true,
+				DefaultFunc:      schema.EnvDefaultFunc("SEGMENT_REQUEST_TIMEOUT", "60s"),
+				ValidateDiagFunc: validateDuration,
+			},
+			"max_retries": {
+				Description:  "The maximum number of times a Config API request is retried when it fails with a 429. Requests which don't create or update resources are also retried on a 5xx or a network error. `0` disables retries. Defaults to `10`.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("SEGMENT_MAX_RETRIES", 10),
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"retry_initial_delay": {
+				Description:      "The maximum delay before the first retry of a Config API request, as a Go duration string. The delay doubles after each retry and is randomised to spread retries. A `Retry-After` header sent by Segment takes precedence, up to `retry_max_delay`. Defaults to `75ms`.",
+				Type:             schema.TypeString,
+				Optional:         true,
+				DefaultFunc:      schema.EnvDefaultFunc("SEGMENT_RETRY_INITIAL_DELAY", "75ms"),
+				ValidateDiagFunc: validateDuration,
+			},
+			"retry_max_delay": {
+				Description:      "The maximum delay between two retries of a Config API request, including delays requested by Segment, as a Go duration string. Defaults to `30s`.",
+				Type:             schema.TypeString,
+				Optional:         true,
+				DefaultFunc:      schema.EnvDefaultFunc("SEGMENT_RETRY_MAX_DELAY", "30s"),
+				ValidateDiagFunc: validateDuration,
+			},
+			"requests_per_second": {
+				Description:  "The maximum average number of Config API requests per second, shared by all the resources managed by the provider. `0` disables the limit. Defaults to `0`.",
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("SEGMENT_REQUESTS_PER_SECOND", 0),
+				ValidateFunc: validation.FloatAtLeast(0),
+			},
+			"burst": {
+				Description:  "The maximum number of Config API requests sent at once before `requests_per_second` applies. Defaults to `5`.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("SEGMENT_BURST", 5),
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"tracking_plan_cache_concurrency": {
+				Description:  "The maximum number of tracking plans whose source connections are listed at once, when looking for the tracking plan of a source. Defaults to `8`.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("SEGMENT_TRACKING_PLAN_CACHE_CONCURRENCY", 8),
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"debug_http": {
+				Description: "Log every Config API request and response, including their bodies, at the `INFO` level. The access token and the values of `password` destination configs are redacted. Requests are also logged to the `segment_api` subsystem at the `DEBUG` level when `TF_LOG` or `TF_LOG_PROVIDER_SEGMENT_API` is `DEBUG` or `TRACE`. Defaults to `false`.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SEGMENT_DEBUG_HTTP", false),
+			},
+			"skip_credentials_validation": {
+				Description: "Skip checking that the access token can access the workspace and its tracking plans when configuring the provider, and the workspaces overridden by resources when first using them, e.g. to validate a configuration offline or to manage destinations with a token missing the Protocols scope. Defaults to `false`.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SEGMENT_SKIP_CREDENTIALS_VALIDATION", false),
+			},
+			"read_only": {
+				Description: "Refuse every Config API call creating, updating or deleting something in the workspace, so plans and refreshes can safely run with production credentials. Applying a change fails with an error. Defaults to `false`.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SEGMENT_READ_ONLY", false),
+			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
 			"segment_tracking_plan":      resourceTrackingPlan(),
@@ -44,35 +155,160 @@ func New() *schema.Provider {
 			"segment_destination_filter": resourceSegmentDestinationFilter(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"segment_event_library": dataSourceEventLibrary(),
+			"segment_event_library":       dataSourceEventLibrary(),
+			"segment_source":              dataSourceSegmentSource(),
+			"segment_sources":             dataSourceSegmentSources(),
+			"segment_catalog_source":      dataSourceSegmentCatalogSource(),
+			"segment_catalog_destination": dataSourceSegmentCatalogDestination(),
 		},
 		ConfigureContextFunc: providerConfigure,
 	}
 }
 
-func providerConfigure(_ context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
 	var diags diag.Diagnostics
 
-	accessToken := d.Get("access_token").(string)
 	workSpace := d.Get("workspace").(string)
+	ctx, span := tracer.Start(ctx, "configure provider", trace.WithAttributes(attribute.String("segment.workspace", workSpace)))
+	defer span.End()
+	ctx = utils.WithLogging(ctx, map[string]interface{}{"workspace": workSpace})
+	tokens, err := tokenSource(d)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to create Segment Config API client",
+			Detail:   err.Error(),
+		})
+		return nil, diags
+	}
+
+	if tokens == nil || workSpace == "" {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to create Segment Config API client",
+			Detail:   "Access token and workspace values cannot be empty",
+		})
+		return nil, diags
+	}
+
+	httpClient, err := newHTTPClient(d)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to create Segment Config API client",
+			Detail:   err.Error(),
+		})
+		return nil, diags
+	}
+
+	limiter := configapi.NewRateLimiter(d.Get("requests_per_second").(float64), d.Get("burst").(int))
+	retry := retryPolicy(d)
+	readOnly := d.Get("read_only").(bool)
+	workspaces := NewWorkspaceClients(func(workspace string) SegmentAPI {
+		var c SegmentAPI = configapi.NewClient("", workspace,
+			configapi.WithTokenSource(tokens),
+			configapi.WithBaseURL(d.Get("endpoint").(string)),
+			configapi.WithHTTPClient(httpClient),
+			configapi.WithRetryPolicy(retry),
+			configapi.WithRateLimiter(limiter),
+		)
+		if readOnly {
+			c = readOnlyClient{c}
+		}
+
+		return c
+	}, d.Get("tracking_plan_cache_concurrency").(int))
+	workspaces.checkCredentials = !d.Get("skip_credentials_validation").(bool)
+	defaultWorkspace := workspaces.get(workSpace)
+
+	// The workspaces overridden by resources are validated when first used
+	diags = append(diags, workspaces.validate(ctx, workSpace)...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	if readOnly {
+		tflog.Info(ctx, "The provider is read-only, changes to the workspaces will be refused")
+	}
+
+	return ProviderMetadata{
+		Client:                           defaultWorkspace.client,
+		Workspace:                        workSpace,
+		Workspaces:                       workspaces,
+		RateLimiter:                      limiter,
+		TrackingPlansConnections:         defaultWorkspace.connections,
+		IsDestinationConfigPropSupported: isDestinationConfigPropSupported(d),
+	}, diags
+}
+
+// tokenSource returns where to get the access token from, or nil if none is configured
+func tokenSource(d *schema.ResourceData) (configapi.TokenSource, error) {
+	if command := d.Get("access_token_command").(string); command != "" {
+		return configapi.NewCommandTokenSource(command), nil
+	}
 
-	if accessToken != "" && workSpace != "" {
-		c := segment.NewClient(accessToken, workSpace)
-		if c != nil {
-			return ProviderMetadata{
-				Client:                           *c,
-				Workspace:                        workSpace,
-				IsDestinationConfigPropSupported: isDestinationConfigPropSupported(d),
-			}, diags
+	if path := d.Get("access_token_file").(string); path != "" {
+		token, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the access token file: %w", err)
 		}
+		if strings.TrimSpace(string(token)) == "" {
+			return nil, fmt.Errorf("the access token file %s is empty", path)
+		}
+
+		return configapi.StaticToken(strings.TrimSpace(string(token))), nil
+	}
+
+	if token := d.Get("access_token").(string); token != "" {
+		return configapi.StaticToken(token), nil
+	}
+
+	return nil, nil
+}
+
+// newHTTPClient builds the HTTP client used to reach the Config API from the transport settings of the provider
+func newHTTPClient(d *schema.ResourceData) (*http.Client, error) {
+	// The value has already been validated by validateDuration
+	timeout, _ := time.ParseDuration(d.Get("request_timeout").(string))
+
+	config := configapi.TransportConfig{
+		ProxyURL:   d.Get("http_proxy").(string),
+		CACertFile: d.Get("ca_cert_file").(string),
+		Timeout:    timeout,
+	}
+	if d.Get("debug_http").(bool) {
+		config.Trace, config.TraceLevel = true, "INFO"
+	} else if logging.IsDebugOrHigher() || utils.IsDebugOrHigher(utils.LogSegmentAPI) {
+		config.Trace, config.TraceLevel = true, "DEBUG"
+	}
+
+	return configapi.NewHTTPClient(config)
+}
+
+// retryPolicy builds the policy used to retry failing Config API requests from the settings of the provider
+func retryPolicy(d *schema.ResourceData) utils.RetryPolicy {
+	// The values have already been validated by validateDuration
+	initialDelay, _ := time.ParseDuration(d.Get("retry_initial_delay").(string))
+	maxDelay, _ := time.ParseDuration(d.Get("retry_max_delay").(string))
+
+	return utils.RetryPolicy{
+		MaxRetries:   d.Get("max_retries").(int),
+		InitialDelay: initialDelay,
+		MaxDelay:     maxDelay,
+	}
+}
+
+func validateDuration(i interface{}, path cty.Path) diag.Diagnostics {
+	if _, err := time.ParseDuration(i.(string)); err != nil {
+		return diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Invalid duration",
+			Detail:        err.Error(),
+			AttributePath: path,
+		}}
 	}
 
-	diags = append(diags, diag.Diagnostic{
-		Severity: diag.Error,
-		Summary:  "Unable to create Segment Config API client",
-		Detail:   "Access token and workspace values cannot be empty",
-	})
-	return nil, diags
+	return nil
 }
 
 // Provides a way to skip some destination configuration properties when sending them through the Config API.
@@ -80,18 +316,15 @@ func providerConfigure(_ context.Context, d *schema.ResourceData) (interface{},
 func isDestinationConfigPropSupported(d *schema.ResourceData) func(destination string, key string) bool {
 	return func(destination string, key string) bool {
 		exclusions := d.Get("unsupported_destination_config_props").(*schema.Set)
-		excluded := exclusions.Contains(destination+"/"+key) || exclusions.Contains(key)
-
-		if excluded {
-			log.Printf("Excluding config %s/%s", destination, key)
-		}
-
-		return !excluded
+		return !exclusions.Contains(destination+"/"+key) && !exclusions.Contains(key)
 	}
 }
 
 type ProviderMetadata struct {
-	Client                           segment.Client
+	Client                           SegmentAPI
 	Workspace                        string
+	Workspaces                       *WorkspaceClients      // Clients of the workspaces overridden by resources
+	RateLimiter                      *configapi.RateLimiter // Shared by every Config API call of the provider
+	TrackingPlansConnections         *TrackingPlansConnectionsCache
 	IsDestinationConfigPropSupported func(destination string, key string) bool
 }
@@ -0,0 +1,284 @@
+package provider_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uswitch/terraform-provider-segment/internal/configapi"
+	"github.com/uswitch/terraform-provider-segment/internal/fake"
+	"github.com/uswitch/terraform-provider-segment/internal/mockapi"
+	"github.com/uswitch/terraform-provider-segment/internal/provider"
+)
+
+// configureProvider configures a provider against a mock Config API server
+func configureProvider(t *testing.T, server *mockapi.Server, config map[string]interface{}) provider.ProviderMetadata {
+	t.Helper()
+
+	p := provider.New()
+	diags := configure(p, server, config)
+	require.False(t, diags.HasError(), "%v", diags)
+
+	return p.Meta().(provider.ProviderMetadata)
+}
+
+func configure(p *schema.Provider, server *mockapi.Server, config map[string]interface{}) diag.Diagnostics {
+	raw := map[string]interface{}{
+		"access_token": server.Token,
+		"workspace":    server.Workspace.Slug(),
+		"endpoint":     server.URL,
+	}
+	for k, v := range config {
+		raw[k] = v
+	}
+
+	return p.Configure(context.Background(), terraform.NewResourceConfigRaw(raw))
+}
+
+func TestProvider_retries(t *testing.T) {
+	server := mockapi.NewServer(unitTestWorkspace, "token")
+	defer server.Close()
+	server.InjectFault(fake.Fault{Method: "ListTrackingPlans", Code: http.StatusTooManyRequests, Times: 1})
+	server.InjectFault(fake.Fault{Method: "ListTrackingPlans", Code: http.StatusBadGateway, Times: 1})
+
+	meta := configureProvider(t, server, map[string]interface{}{
+		"max_retries":                 2,
+		"retry_initial_delay":         "1ms",
+		"skip_credentials_validation": true,
+	})
+	_, err := meta.Client.ListTrackingPlans(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, server.Workspace.CallCount("ListTrackingPlans"))
+}
+
+func TestProvider_retriesDisabled(t *testing.T) {
+	server := mockapi.NewServer(unitTestWorkspace, "token")
+	defer server.Close()
+	server.InjectFault(fake.Fault{Method: "ListTrackingPlans", Code: http.StatusBadGateway, Times: 1})
+
+	meta := configureProvider(t, server, map[string]interface{}{
+		"max_retries":                 0,
+		"skip_credentials_validation": true,
+	})
+	_, err := meta.Client.ListTrackingPlans(context.Background())
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, server.Workspace.CallCount("ListTrackingPlans"))
+}
+
+func TestProvider_sharesRateLimiter(t *testing.T) {
+	server := mockapi.NewServer(unitTestWorkspace, "token")
+	defer server.Close()
+
+	meta := configureProvider(t, server, map[string]interface{}{
+		"requests_per_second":         50,
+		"burst":                       2,
+		"skip_credentials_validation": true,
+	})
+	require.NotNil(t, meta.RateLimiter)
+
+	ctx := configapi.WithThrottleCounter(context.Background())
+	for i := 0; i < 4; i++ {
+		_, err := meta.Client.ListTrackingPlans(ctx)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 2, configapi.ThrottledCount(ctx))
+}
+
+func TestProvider_validatesCredentials(t *testing.T) {
+	server := mockapi.NewServer(unitTestWorkspace, "token")
+	defer server.Close()
+
+	configureProvider(t, server, nil)
+
+	assert.Equal(t, 1, server.Workspace.CallCount("GetWorkspace"))
+	assert.Equal(t, 1, server.Workspace.CallCount("ListTrackingPlans"))
+}
+
+func TestProvider_invalidToken(t *testing.T) {
+	server := mockapi.NewServer(unitTestWorkspace, "token")
+	defer server.Close()
+
+	diags := configure(provider.New(), server, map[string]interface{}{"access_token": "wrong"})
+
+	require.Len(t, diags, 1)
+	assert.Equal(t, diag.Error, diags[0].Severity)
+	assert.Equal(t, "Invalid Segment access token", diags[0].Summary)
+	for _, source := range []string{"access_token_command", "access_token_file", "access_token"} {
+		assert.Contains(t, diags[0].Detail, "`"+source+"`")
+	}
+}
+
+func TestProvider_unknownWorkspace(t *testing.T) {
+	server := mockapi.NewServer(unitTestWorkspace, "token")
+	defer server.Close()
+
+	diags := configure(provider.New(), server, map[string]interface{}{"workspace": "typo"})
+
+	require.Len(t, diags, 1)
+	assert.Equal(t, diag.Error, diags[0].Severity)
+	assert.Equal(t, "Unknown Segment workspace", diags[0].Summary)
+	assert.Equal(t, cty.GetAttrPath("workspace"), diags[0].AttributePath)
+}
+
+func TestProvider_missingProtocolsScope(t *testing.T) {
+	server := mockapi.NewServer(unitTestWorkspace, "token")
+	defer server.Close()
+	server.InjectFault(fake.Fault{Method: "ListTrackingPlans", Code: http.StatusForbidden, Times: 1})
+
+	diags := configure(provider.New(), server, nil)
+
+	require.Len(t, diags, 1)
+	assert.Equal(t, diag.Error, diags[0].Severity)
+	assert.Equal(t, "Missing Segment Protocols scope", diags[0].Summary)
+	assert.Contains(t, diags[0].Detail, "skip_credentials_validation")
+}
+
+func TestProvider_missingProtocolsScopeSkipped(t *testing.T) {
+	ctx := context.Background()
+	server := mockapi.NewServer(unitTestWorkspace, "token")
+	defer server.Close()
+	_, err := server.Workspace.CreateSource(ctx, "existing", "catalog/sources/javascript")
+	require.NoError(t, err)
+	server.InjectFault(fake.Fault{Method: "ListTrackingPlans", Code: http.StatusForbidden, Times: 1})
+
+	// Configurations managing destinations only don't need the Protocols scope
+	meta := configureProvider(t, server, map[string]interface{}{"skip_credentials_validation": true})
+	destination := newUnitTestResourceWithMeta(t, "segment_destination", meta)
+	require.Empty(t, destination.apply(map[string]interface{}{
+		"source":  "existing",
+		"name":    "google-analytics",
+		"enabled": true,
+		"config":  map[string]interface{}{},
+	}))
+
+	source := newUnitTestResourceWithMeta(t, "segment_source", meta)
+	d := source.importState("existing")
+
+	require.Len(t, d, 1)
+	assert.Equal(t, "Missing Segment Protocols scope", d[0].Summary)
+	assert.Equal(t, cty.GetAttrPath("tracking_plan"), d[0].AttributePath)
+}
+
+func TestProvider_validatesOverriddenWorkspaces(t *testing.T) {
+	server := mockapi.NewServer(unitTestWorkspace, "token")
+	defer server.Close()
+	meta := configureProvider(t, server, nil)
+	config := map[string]interface{}{
+		"workspace":    "typo",
+		"source_name":  "my-source",
+		"catalog_name": "catalog/sources/javascript",
+	}
+
+	r := newUnitTestResourceWithMeta(t, "segment_source", meta)
+	d := r.apply(config)
+
+	require.Len(t, d, 1)
+	assert.Equal(t, "Unknown Segment workspace", d[0].Summary)
+	assert.Equal(t, cty.GetAttrPath("workspace"), d[0].AttributePath)
+	assert.Zero(t, server.Workspace.CallCount("CreateSource"))
+
+	// The workspace of the provider is only validated once
+	config["workspace"] = unitTestWorkspace
+	r = newUnitTestResourceWithMeta(t, "segment_source", meta)
+	require.Empty(t, r.apply(config))
+	assert.Equal(t, 1, server.Workspace.CallCount("GetWorkspace"))
+}
+
+func TestProvider_skipCredentialsValidation(t *testing.T) {
+	server := mockapi.NewServer(unitTestWorkspace, "token")
+	defer server.Close()
+
+	diags := configure(provider.New(), server, map[string]interface{}{
+		"access_token":                "wrong",
+		"skip_credentials_validation": true,
+	})
+
+	assert.Empty(t, diags)
+	assert.Zero(t, server.Workspace.CallCount(""))
+}
+
+func TestProvider_readOnly(t *testing.T) {
+	ctx := context.Background()
+	server := mockapi.NewServer(unitTestWorkspace, "token")
+	defer server.Close()
+	_, err := server.Workspace.CreateSource(ctx, "existing", "catalog/sources/javascript")
+	require.NoError(t, err)
+
+	meta := configureProvider(t, server, map[string]interface{}{"read_only": true})
+
+	// Reads work normally
+	existing := newUnitTestResourceWithMeta(t, "segment_source", meta)
+	require.Empty(t, existing.importState("existing"))
+	assert.Equal(t, "catalog/sources/javascript", existing.attr("catalog_name"))
+
+	// Mutations are refused before reaching the API
+	created := newUnitTestResourceWithMeta(t, "segment_source", meta)
+	d := created.apply(map[string]interface{}{
+		"source_name":  "new",
+		"catalog_name": "catalog/sources/javascript",
+	})
+	require.Len(t, d, 1)
+	assert.Equal(t, "Read-only provider", d[0].Summary)
+	assert.Contains(t, d[0].Detail, "refusing to create source new")
+
+	assert.True(t, existing.destroy().HasError())
+	assert.Error(t, meta.Client.CreateTrackingPlanSourceConnection(ctx, "rs_123", "existing"))
+	assert.Equal(t, 1, server.Workspace.CallCount("CreateSource"), "only the existing source should have been created")
+	assert.Zero(t, server.Workspace.CallCount("DeleteSource"))
+	assert.Zero(t, server.Workspace.CallCount("CreateTrackingPlanSourceConnection"))
+}
+
+func TestProvider_accessTokenFile(t *testing.T) {
+	server := mockapi.NewServer(unitTestWorkspace, "token")
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(path, []byte("token\n"), 0600))
+
+	configureProvider(t, server, map[string]interface{}{
+		"access_token":      "",
+		"access_token_file": path,
+	})
+
+	// The file is read again when the provider is configured again, e.g. after a rotation
+	require.NoError(t, os.WriteFile(path, []byte("rotated"), 0600))
+	diags := configure(provider.New(), server, map[string]interface{}{
+		"access_token":      "",
+		"access_token_file": path,
+	})
+	require.Len(t, diags, 1)
+	assert.Equal(t, "Invalid Segment access token", diags[0].Summary)
+}
+
+func TestProvider_accessTokenCommand(t *testing.T) {
+	server := mockapi.NewServer(unitTestWorkspace, "token")
+	defer server.Close()
+
+	meta := configureProvider(t, server, map[string]interface{}{
+		"access_token":         "ignored",
+		"access_token_command": "echo token",
+	})
+	_, err := meta.Client.ListTrackingPlans(context.Background())
+
+	assert.NoError(t, err)
+}
+
+func TestProvider_missingAccessToken(t *testing.T) {
+	server := mockapi.NewServer(unitTestWorkspace, "token")
+	defer server.Close()
+
+	diags := configure(provider.New(), server, map[string]interface{}{"access_token": ""})
+
+	require.Len(t, diags, 1)
+	assert.Equal(t, "Unable to create Segment Config API client", diags[0].Summary)
+}
@@ -22,7 +22,7 @@ var testAccProviders map[string]func() (*schema.Provider, error)
 var testAccProviderConfigure sync.Once
 
 func init() {
-	testAccProvider = provider.Provider()
+	testAccProvider = provider.New()
 	testAccProviders = map[string]func() (*schema.Provider, error){
 		"segment": func() (*schema.Provider, error) { return testAccProvider, nil },
 	}
@@ -0,0 +1,159 @@
+package provider_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/require"
+	"github.com/uswitch/terraform-provider-segment/internal/fake"
+	"github.com/uswitch/terraform-provider-segment/internal/provider"
+)
+
+const (
+	unitTestWorkspace   = "unit-test"
+	unitTestConcurrency = 4
+)
+
+var _ provider.SegmentAPI = (*fake.Workspace)(nil)
+
+// unitTestResource drives the lifecycle of a single resource against an in-memory workspace, the same way Terraform would.
+// It allows to unit test resources without a terraform binary nor a live Segment workspace.
+// Example:
+// 	ws := fake.NewWorkspace(unitTestWorkspace)
+// 	r := newUnitTestResource(t, "segment_source", ws)
+// 	r.apply(map[string]interface{}{"source_name": "foo", "catalog_name": "catalog/sources/javascript"})
+// 	r.destroy()
+type unitTestResource struct {
+	t        *testing.T
+	ctx      context.Context // Passed to every operation, e.g. to capture logs
+	resource *schema.Resource
+	meta     provider.ProviderMetadata
+	state    *terraform.InstanceState
+}
+
+func newUnitTestResource(t *testing.T, resourceType string, ws *fake.Workspace) *unitTestResource {
+	return newUnitTestResourceWithMeta(t, resourceType, unitTestMeta(ws))
+}
+
+// newUnitTestResourceWithMeta creates a resource sharing the metadata of other resources, as within a single Terraform run
+func newUnitTestResourceWithMeta(t *testing.T, resourceType string, meta provider.ProviderMetadata) *unitTestResource {
+	r, ok := provider.New().ResourcesMap[resourceType]
+	require.True(t, ok, "unknown resource type %s", resourceType)
+
+	return &unitTestResource{
+		t:        t,
+		ctx:      context.Background(),
+		resource: r,
+		meta:     meta,
+	}
+}
+
+func unitTestMeta(ws *fake.Workspace) provider.ProviderMetadata {
+	return provider.ProviderMetadata{
+		Client:                           ws,
+		Workspace:                        ws.Slug(),
+		TrackingPlansConnections:         provider.NewTrackingPlansConnectionsCache(unitTestConcurrency),
+		IsDestinationConfigPropSupported: func(string, string) bool { return true },
+	}
+}
+
+// readDataSource reads a data source with the given config, the same way Terraform would while planning
+func readDataSource(t *testing.T, dataSourceType string, meta provider.ProviderMetadata, config map[string]interface{}) (*schema.ResourceData, diag.Diagnostics) {
+	t.Helper()
+
+	ds, ok := provider.New().DataSourcesMap[dataSourceType]
+	require.True(t, ok, "unknown data source type %s", dataSourceType)
+	d := schema.TestResourceDataRaw(t, ds.Schema, config)
+
+	return d, ds.ReadContext(context.Background(), d, meta)
+}
+
+// apply plans the config against the current state and applies the resulting diff, if any
+func (r *unitTestResource) apply(config map[string]interface{}) diag.Diagnostics {
+	r.t.Helper()
+
+	diff, err := r.resource.Diff(r.ctx, r.state, terraform.NewResourceConfigRaw(config), r.meta)
+	require.NoError(r.t, err)
+	if diff == nil {
+		return nil
+	}
+
+	state, diags := r.resource.Apply(r.ctx, r.state, diff, r.meta)
+	r.state = state
+
+	return diags
+}
+
+// plan returns the diff between the config and the current state, or nil if there are no changes
+func (r *unitTestResource) plan(config map[string]interface{}) *terraform.InstanceDiff {
+	r.t.Helper()
+
+	diff, err := r.resource.Diff(r.ctx, r.state, terraform.NewResourceConfigRaw(config), r.meta)
+	require.NoError(r.t, err)
+
+	return diff
+}
+
+// planError returns the error planning the config fails with, e.g. because of a CustomizeDiff, or nil
+func (r *unitTestResource) planError(config map[string]interface{}) error {
+	_, err := r.resource.Diff(r.ctx, r.state, terraform.NewResourceConfigRaw(config), r.meta)
+
+	return err
+}
+
+// refresh reads the resource and updates the state
+func (r *unitTestResource) refresh() diag.Diagnostics {
+	r.t.Helper()
+	require.NotNil(r.t, r.state, "refreshing a resource which hasn't been created")
+
+	state, diags := r.resource.RefreshWithoutUpgrade(r.ctx, r.state, r.meta)
+	r.state = state
+
+	return diags
+}
+
+// importState imports an existing resource by ID and reads it, the same way `terraform import` would
+func (r *unitTestResource) importState(id string) diag.Diagnostics {
+	r.t.Helper()
+
+	data := r.resource.Data(&terraform.InstanceState{ID: id, Attributes: map[string]string{"id": id}})
+	imported, err := r.resource.Importer.StateContext(r.ctx, data, r.meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	require.Len(r.t, imported, 1)
+	r.state = imported[0].State()
+
+	return r.refresh()
+}
+
+// destroy deletes the resource
+func (r *unitTestResource) destroy() diag.Diagnostics {
+	r.t.Helper()
+	require.NotNil(r.t, r.state, "destroying a resource which hasn't been created")
+
+	state, diags := r.resource.Apply(r.ctx, r.state, &terraform.InstanceDiff{Destroy: true}, r.meta)
+	r.state = state
+
+	return diags
+}
+
+// attr returns the value of an attribute in the current state
+func (r *unitTestResource) attr(key string) string {
+	r.t.Helper()
+	require.NotNil(r.t, r.state, "resource has no state")
+
+	return r.state.Attributes[key]
+}
+
+// id returns the ID of the resource in the current state
+func (r *unitTestResource) id() string {
+	if r.state == nil {
+		return ""
+	}
+
+	return r.state.ID
+}
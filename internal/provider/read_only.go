@@ -0,0 +1,145 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/uswitch/segment-config-go/segment"
+	"github.com/uswitch/terraform-provider-segment/internal/configapi"
+)
+
+// readOnlyError is returned instead of performing a mutating call when the provider is read-only
+type readOnlyError struct {
+	operation string
+}
+
+func (e *readOnlyError) Error() string {
+	return fmt.Sprintf("refusing to %s as the provider is read-only", e.operation)
+}
+
+func refuse(format string, args ...interface{}) error {
+	return &readOnlyError{operation: fmt.Sprintf(format, args...)}
+}
+
+// readOnlyClient forwards read calls to the wrapped client and fails every call mutating the workspace, so plans and
+// refreshes can run with production credentials without any risk of changing it.
+// It doesn't embed SegmentAPI, so a method added to the interface doesn't compile until it is forwarded or refused here.
+type readOnlyClient struct {
+	client SegmentAPI
+}
+
+var _ SegmentAPI = readOnlyClient{}
+
+// Workspaces
+
+func (c readOnlyClient) GetWorkspace(ctx context.Context) (segment.Workspace, error) {
+	return c.client.GetWorkspace(ctx)
+}
+
+// Sources
+
+func (c readOnlyClient) ListSources(ctx context.Context) ([]configapi.Source, error) {
+	return c.client.ListSources(ctx)
+}
+
+func (c readOnlyClient) GetSource(ctx context.Context, srcName string) (configapi.Source, error) {
+	return c.client.GetSource(ctx, srcName)
+}
+
+func (c readOnlyClient) CreateSource(_ context.Context, srcName string, _ string) (configapi.Source, error) {
+	return configapi.Source{}, refuse("create source %s", srcName)
+}
+
+func (c readOnlyClient) DeleteSource(_ context.Context, srcName string) error {
+	return refuse("delete source %s", srcName)
+}
+
+func (c readOnlyClient) GetSourceConfig(ctx context.Context, srcName string) (segment.SourceConfig, error) {
+	return c.client.GetSourceConfig(ctx, srcName)
+}
+
+func (c readOnlyClient) UpdateSourceConfig(_ context.Context, srcName string, _ segment.SourceConfig) (segment.SourceConfig, error) {
+	return segment.SourceConfig{}, refuse("update the schema config of source %s", srcName)
+}
+
+// Destinations
+
+func (c readOnlyClient) GetDestination(ctx context.Context, srcName string, destName string) (segment.Destination, error) {
+	return c.client.GetDestination(ctx, srcName, destName)
+}
+
+func (c readOnlyClient) CreateDestination(_ context.Context, srcName string, destName string, _ string, _ bool, _ []segment.DestinationConfig) (segment.Destination, error) {
+	return segment.Destination{}, refuse("create destination %s", destinationResourceId(srcName, destName))
+}
+
+func (c readOnlyClient) UpdateDestination(_ context.Context, srcName string, destName string, _ bool, _ []segment.DestinationConfig) (segment.Destination, error) {
+	return segment.Destination{}, refuse("update destination %s", destinationResourceId(srcName, destName))
+}
+
+func (c readOnlyClient) DeleteDestination(_ context.Context, srcName string, destName string) error {
+	return refuse("delete destination %s", destinationResourceId(srcName, destName))
+}
+
+// Destination filters
+
+func (c readOnlyClient) GetDestinationFilter(ctx context.Context, srcName string, destName string, filterID string) (*segment.DestinationFilter, error) {
+	return c.client.GetDestinationFilter(ctx, srcName, destName, filterID)
+}
+
+func (c readOnlyClient) CreateDestinationFilter(_ context.Context, srcName string, destName string, _ segment.DestinationFilter) (*segment.DestinationFilter, error) {
+	return nil, refuse("create a filter for destination %s", destinationResourceId(srcName, destName))
+}
+
+func (c readOnlyClient) UpdateDestinationFilter(_ context.Context, _ string, _ string, filter segment.DestinationFilter) (*segment.DestinationFilter, error) {
+	return nil, refuse("update destination filter %s", filter.Name)
+}
+
+func (c readOnlyClient) DeleteDestinationFilter(_ context.Context, srcName string, destName string, filterID string) error {
+	return refuse("delete filter %s of destination %s", filterID, destinationResourceId(srcName, destName))
+}
+
+// Tracking plans
+
+func (c readOnlyClient) ListTrackingPlans(ctx context.Context) (segment.TrackingPlans, error) {
+	return c.client.ListTrackingPlans(ctx)
+}
+
+func (c readOnlyClient) GetTrackingPlan(ctx context.Context, trackingPlanID string) (segment.TrackingPlan, error) {
+	return c.client.GetTrackingPlan(ctx, trackingPlanID)
+}
+
+func (c readOnlyClient) CreateTrackingPlan(_ context.Context, data segment.TrackingPlan) (segment.TrackingPlan, error) {
+	return segment.TrackingPlan{}, refuse("create tracking plan %s", data.DisplayName)
+}
+
+func (c readOnlyClient) UpdateTrackingPlan(_ context.Context, trackingPlanID string, _ segment.TrackingPlan) (segment.TrackingPlan, error) {
+	return segment.TrackingPlan{}, refuse("update tracking plan %s", trackingPlanID)
+}
+
+func (c readOnlyClient) DeleteTrackingPlan(_ context.Context, trackingPlanID string) error {
+	return refuse("delete tracking plan %s", trackingPlanID)
+}
+
+// Tracking plan source connections
+
+func (c readOnlyClient) ListTrackingPlanSources(ctx context.Context, planID string) ([]segment.TrackingPlanSourceConnection, error) {
+	return c.client.ListTrackingPlanSources(ctx, planID)
+}
+
+func (c readOnlyClient) CreateTrackingPlanSourceConnection(_ context.Context, planID string, srcName string) error {
+	return refuse("connect source %s to tracking plan %s", srcName, planID)
+}
+
+func (c readOnlyClient) DeleteTrackingPlanSourceConnection(_ context.Context, planID string, srcName string) error {
+	return refuse("disconnect source %s from tracking plan %s", srcName, planID)
+}
+
+// Catalog
+
+func (c readOnlyClient) GetCatalogSource(ctx context.Context, srcName string) (configapi.CatalogEntry, error) {
+	return c.client.GetCatalogSource(ctx, srcName)
+}
+
+func (c readOnlyClient) GetCatalogDestination(ctx context.Context, destName string) (configapi.CatalogEntry, error) {
+	return c.client.GetCatalogDestination(ctx, destName)
+}
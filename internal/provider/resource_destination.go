@@ -5,11 +5,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"reflect"
 	"strings"
 
 	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
@@ -27,12 +27,20 @@ const (
 	keyDestConMode     = "connection_mode"
 	keyDestConfig      = "config"
 	keyDestParent      = "parent"
+	keyDestOnDestroy   = "on_destroy"
+)
+
+// What destroying a destination does
+const (
+	onDestroyDelete  = "delete"
+	onDestroyDisable = "disable"
 )
 
 func resourceSegmentDestination() *schema.Resource {
 	return &schema.Resource{
 		Description: "A destination connection on Segment. More information on destinations and how to use them can be found in the [Segment Destinations documentation](https://segment.com/docs/connections/destinations/).",
 		Schema: map[string]*schema.Schema{
+			keyWorkspace: workspaceSchema("destination"),
 			keyDestSource: {
 				Description: "The Segment source name this destination is connecting to.",
 				Type:        schema.TypeString,
@@ -85,29 +93,45 @@ func resourceSegmentDestination() *schema.Resource {
 				},
 				ValidateDiagFunc: validateDestinationConfig,
 			},
+			keyDestOnDestroy: {
+				Description: "What destroying the destination does. `delete` deletes it from Segment. `disable` only disables it, " +
+					"keeping the settings Terraform doesn't manage, e.g. `select` configs. Creating it again with `disable` re-enables " +
+					"it, provided it is still disabled and has the same `connection_mode`, other existing destinations must be " +
+					"imported. Available values are: `delete`, `disable`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      onDestroyDelete,
+				ValidateFunc: validation.StringInSlice([]string{onDestroyDelete, onDestroyDisable}, false),
+			},
 		},
-		CreateContext: resourceSegmentDestinationCreate,
-		ReadContext:   resourceSegmentDestinationRead,
-		UpdateContext: resourceSegmentDestinationUpdate,
-		DeleteContext: resourceSegmentDestinationDelete,
+		CreateContext: operation("create segment_destination", resourceSegmentDestinationCreate),
+		ReadContext:   operation("read segment_destination", resourceSegmentDestinationRead),
+		UpdateContext: operation("update segment_destination", resourceSegmentDestinationUpdate),
+		DeleteContext: operation("delete segment_destination", resourceSegmentDestinationDelete),
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: importWithDefaults(importWithWorkspace(2), map[string]interface{}{keyDestOnDestroy: onDestroyDelete}),
 		},
+		Timeouts: resourceTimeouts(),
 	}
 }
 
 func resourceSegmentDestinationRead(c context.Context, r *schema.ResourceData, m interface{}) diag.Diagnostics {
-	meta := m.(ProviderMetadata)
+	meta := resourceMeta(m, r)
 	client := meta.Client
 	srcName, dstName := destinationIdToSourceAndDest(r.Id())
 
-	d, err := client.GetDestination(srcName, dstName)
+	d, err := client.GetDestination(c, srcName, dstName)
+	if utils.IsNotFound(err) && !r.IsNewResource() {
+		tflog.Warn(c, "Destination not found, removing it from the state", map[string]interface{}{"id": r.Id()})
+		r.SetId("")
+		return nil
+	}
 	if err != nil {
-		return diag.FromErr(err)
+		return apiErrorDiag(err, "read destination "+r.Id(), cty.GetAttrPath(keyDestName))
 	}
 
 	config := map[string]interface{}{}
-	utils.CatchFirst(
+	return utils.CatchFirst(
 		func() error { return encodeDestinationConfig(d, &config) },
 		func() error { return r.Set(keyDestSource, srcName) },
 		func() error { return r.Set(keyDestName, utils.PathToName(d.Name)) },
@@ -119,12 +143,10 @@ func resourceSegmentDestinationRead(c context.Context, r *schema.ResourceData, m
 		func() error { return r.Set(keyDestCreateTime, d.CreateTime.String()) },
 		func() error { return r.Set(keyDestUpdateTime, d.UpdateTime.String()) },
 	)
-
-	return nil
 }
 
 func resourceSegmentDestinationUpdate(ctx context.Context, r *schema.ResourceData, m interface{}) diag.Diagnostics {
-	meta := m.(ProviderMetadata)
+	meta := resourceMeta(m, r)
 	client := meta.Client
 	srcName := r.Get(keyDestSource).(string)
 	destName := r.Get(keyDestName).(string)
@@ -132,19 +154,19 @@ func resourceSegmentDestinationUpdate(ctx context.Context, r *schema.ResourceDat
 	rawConfig := r.Get(keyDestConfig).(map[string]interface{})
 
 	config := []segment.DestinationConfig{}
-	if d := decodeDestinationConfig(meta.Workspace, srcName, destName, rawConfig, &config, meta.IsDestinationConfigPropSupported); d != nil {
+	if d := decodeDestinationConfig(ctx, meta.Workspace, srcName, destName, rawConfig, &config, meta.IsDestinationConfigPropSupported); d != nil {
 		return d
 	}
 
-	if _, err := client.UpdateDestination(srcName, destName, enabled, config); err != nil {
-		return diag.FromErr(err)
+	if _, err := client.UpdateDestination(ctx, srcName, destName, enabled, config); err != nil {
+		return destinationConfigErrorDiag(err, "update destination "+destinationResourceId(srcName, destName), rawConfig)
 	}
 
 	return resourceSegmentDestinationRead(ctx, r, m)
 }
 
 func resourceSegmentDestinationCreate(ctx context.Context, r *schema.ResourceData, m interface{}) diag.Diagnostics {
-	meta := m.(ProviderMetadata)
+	meta := resourceMeta(m, r)
 	client := meta.Client
 	srcName := r.Get(keyDestSource).(string)
 	destName := r.Get(keyDestName).(string)
@@ -153,33 +175,106 @@ func resourceSegmentDestinationCreate(ctx context.Context, r *schema.ResourceDat
 	mode := r.Get(keyDestConMode).(string)
 	enabled := r.Get(keyDestEnabled).(bool)
 	var config []segment.DestinationConfig
-	if d := decodeDestinationConfig(meta.Workspace, srcName, destName, r.Get("config"), &config, meta.IsDestinationConfigPropSupported); d != nil {
+	if d := decodeDestinationConfig(ctx, meta.Workspace, srcName, destName, r.Get("config"), &config, meta.IsDestinationConfigPropSupported); d != nil {
 		return d
 	}
 
-	log.Printf("[INFO] Creating destination %s for %s", destName, srcName)
-	if _, err := client.CreateDestination(srcName, destName, mode, enabled, config); err != nil {
-		return diag.FromErr(err)
+	// Destinations destroyed with on_destroy = "disable" are left disabled in Segment, and adopted when created again
+	var rollback utils.Rollback
+	adopted, d := adoptDisabledDestination(ctx, r, meta, config)
+	if d != nil {
+		return d
+	}
+	if adopted {
+		rollback.Add("Adopted destination "+id, func(ctx context.Context) error {
+			_, err := client.UpdateDestination(ctx, srcName, destName, false, config)
+			return err
+		})
+	} else {
+		tflog.Info(ctx, "Creating destination", map[string]interface{}{"id": id})
+		if _, err := client.CreateDestination(ctx, srcName, destName, mode, enabled, config); err != nil {
+			return destinationConfigErrorDiag(err, "create destination "+id, r.Get(keyDestConfig).(map[string]interface{}))
+		}
+		rollback.Add("Destination "+id, func(ctx context.Context) error {
+			return client.DeleteDestination(ctx, srcName, destName)
+		})
 	}
 
 	r.SetId(id)
 
-	return resourceSegmentDestinationRead(ctx, r, m)
+	return rollback.OnError(ctx, r, resourceSegmentDestinationRead(ctx, r, m))
 }
 
-func resourceSegmentDestinationDelete(_ context.Context, r *schema.ResourceData, m interface{}) diag.Diagnostics {
-	meta := m.(ProviderMetadata)
+func resourceSegmentDestinationDelete(ctx context.Context, r *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := resourceMeta(m, r)
 	client := meta.Client
 	srcName, destName := destinationIdToSourceAndDest(r.Id())
 
-	err := client.DeleteDestination(srcName, destName)
+	if r.Get(keyDestOnDestroy).(string) == onDestroyDisable {
+		rawConfig := r.Get(keyDestConfig).(map[string]interface{})
+		config := []segment.DestinationConfig{}
+		if d := decodeDestinationConfig(ctx, meta.Workspace, srcName, destName, rawConfig, &config, meta.IsDestinationConfigPropSupported); d != nil {
+			return d
+		}
+
+		tflog.Info(ctx, "Disabling destination instead of deleting it", map[string]interface{}{"id": r.Id()})
+		_, err := client.UpdateDestination(ctx, srcName, destName, false, config)
+		if utils.IsNotFound(err) {
+			tflog.Warn(ctx, "Destination already deleted", map[string]interface{}{"id": r.Id()})
+			return nil
+		}
+		if err != nil {
+			return apiErrorDiag(err, "disable destination "+r.Id(), cty.GetAttrPath(keyDestOnDestroy))
+		}
+
+		return nil
+	}
+
+	err := client.DeleteDestination(ctx, srcName, destName)
 	if err != nil {
-		return diag.FromErr(err)
+		return apiErrorDiag(err, "delete destination "+r.Id(), cty.GetAttrPath(keyDestName))
 	}
 
 	return nil
 }
 
+// adoptDisabledDestination re-enables the destination a resource with on_destroy = "disable" left disabled when it was
+// destroyed. Destinations which are enabled, or whose connection mode differs from the configured one, weren't left by
+// the resource and aren't adopted.
+func adoptDisabledDestination(ctx context.Context, r *schema.ResourceData, meta ProviderMetadata, config []segment.DestinationConfig) (bool, diag.Diagnostics) {
+	if r.Get(keyDestOnDestroy).(string) != onDestroyDisable {
+		return false, nil
+	}
+
+	srcName := r.Get(keyDestSource).(string)
+	destName := r.Get(keyDestName).(string)
+	id := destinationResourceId(srcName, destName)
+	rawConfig := r.Get(keyDestConfig).(map[string]interface{})
+
+	existing, err := meta.Client.GetDestination(ctx, srcName, destName)
+	if utils.IsNotFound(err) || (err == nil && existing.Enabled) {
+		return false, nil
+	}
+	if err != nil {
+		return false, destinationConfigErrorDiag(err, "create destination "+id, rawConfig)
+	}
+	if mode := r.Get(keyDestConMode).(string); mode != "" && mode != existing.ConnectionMode {
+		return false, diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Disabled destination with another connection mode",
+			Detail:        fmt.Sprintf("Destination %s already exists, disabled, with the connection mode %s. Import it or delete it from Segment to create it with the connection mode %s.", id, existing.ConnectionMode, mode),
+			AttributePath: cty.GetAttrPath(keyDestConMode),
+		}}
+	}
+
+	tflog.Info(ctx, "Adopting disabled destination", map[string]interface{}{"id": id})
+	if _, err := meta.Client.UpdateDestination(ctx, srcName, destName, r.Get(keyDestEnabled).(bool), config); err != nil {
+		return false, destinationConfigErrorDiag(err, "adopt disabled destination "+id, rawConfig)
+	}
+
+	return true, nil
+}
+
 // Decoders
 
 func encodeDestinationConfig(destination segment.Destination, encoded *map[string]interface{}) error {
@@ -193,7 +288,7 @@ func encodeDestinationConfig(destination segment.Destination, encoded *map[strin
 			"value": config.Value,
 		})
 		if err != nil {
-			utils.DiagFromErrPtr(err)
+			return fmt.Errorf("failed to encode destination config %s: %w", config.Name, err)
 		}
 
 		(*encoded)[utils.PathToName(config.Name)] = string(c)
@@ -202,7 +297,7 @@ func encodeDestinationConfig(destination segment.Destination, encoded *map[strin
 	return nil
 }
 
-func decodeDestinationConfig(workspace string, srcName string, destName string, rawConfig interface{}, dst *[]segment.DestinationConfig, isPropAllowed func(d string, k string) bool) (diags diag.Diagnostics) {
+func decodeDestinationConfig(ctx context.Context, workspace string, srcName string, destName string, rawConfig interface{}, dst *[]segment.DestinationConfig, isPropAllowed func(d string, k string) bool) (diags diag.Diagnostics) {
 	defer func() {
 		if r := recover(); r != nil {
 			diags = diag.FromErr(fmt.Errorf("failed to decode destination config: %w", r.(error)))
@@ -222,6 +317,10 @@ func decodeDestinationConfig(workspace string, srcName string, destName string,
 		}
 
 		if !isPropAllowed(destName, k) {
+			tflog.Debug(ctx, "Excluding unsupported destination config", map[string]interface{}{
+				"destination": destName,
+				"config":      k,
+			})
 			continue
 		}
 
@@ -266,7 +365,7 @@ func validateConfigValue(config segment.DestinationConfig) diag.Diagnostics {
 
 func validateDestinationConfig(i interface{}, _ cty.Path) diag.Diagnostics {
 	var c []segment.DestinationConfig
-	if d := decodeDestinationConfig("test", "test", "test", i, &c, func(d string, k string) bool { return false }); d != nil {
+	if d := decodeDestinationConfig(context.Background(), "test", "test", "test", i, &c, func(d string, k string) bool { return false }); d != nil {
 		return d
 	}
 	return nil
@@ -7,6 +7,8 @@ import (
 	"path"
 	"strings"
 
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
@@ -78,6 +80,7 @@ func resourceSegmentDestinationFilter() *schema.Resource {
 	return &schema.Resource{
 		Description: "A destination filter which allows control of how events are flowing to destinations. More information on destination filters and how they are used can be found in the [Segment Destination Filters documentation](https://segment.com/docs/connections/destinations/destination-filters/).",
 		Schema: map[string]*schema.Schema{
+			keyWorkspace: workspaceSchema("destination filter"),
 			keyFilterDestination: {
 				Description: "The ID of the destination this filter is associated with.",
 				Type:        schema.TypeString,
@@ -157,24 +160,30 @@ func resourceSegmentDestinationFilter() *schema.Resource {
 				},
 			},
 		},
-		CreateContext: resourceSegmentDestinationFilterCreate,
-		ReadContext:   resourceSegmentDestinationFilterRead,
-		UpdateContext: resourceSegmentDestinationFilterUpdate,
-		DeleteContext: resourceSegmentDestinationFilterDelete,
+		CreateContext: operation("create segment_destination_filter", resourceSegmentDestinationFilterCreate),
+		ReadContext:   operation("read segment_destination_filter", resourceSegmentDestinationFilterRead),
+		UpdateContext: operation("update segment_destination_filter", resourceSegmentDestinationFilterUpdate),
+		DeleteContext: operation("delete segment_destination_filter", resourceSegmentDestinationFilterDelete),
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: importWithWorkspace(3),
 		},
+		Timeouts: resourceTimeouts(),
 	}
 }
 
-func resourceSegmentDestinationFilterRead(_ context.Context, r *schema.ResourceData, m interface{}) diag.Diagnostics {
-	meta := m.(ProviderMetadata)
+func resourceSegmentDestinationFilterRead(ctx context.Context, r *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := resourceMeta(m, r)
 	client := meta.Client
 
 	s, d, f := SplitDestinationFilterId(r.Id())
-	filter, err := client.GetDestinationFilter(s, d, f)
+	filter, err := client.GetDestinationFilter(ctx, s, d, f)
+	if utils.IsNotFound(err) && !r.IsNewResource() {
+		tflog.Warn(ctx, "Destination filter not found, removing it from the state", map[string]interface{}{"id": r.Id()})
+		r.SetId("")
+		return nil
+	}
 	if err != nil {
-		return diag.FromErr(err)
+		return apiErrorDiag(err, "read destination filter "+r.Id(), cty.GetAttrPath(keyFilterDestination))
 	}
 
 	return utils.CatchFirst(
@@ -189,7 +198,7 @@ func resourceSegmentDestinationFilterRead(_ context.Context, r *schema.ResourceD
 }
 
 func resourceSegmentDestinationFilterUpdate(ctx context.Context, r *schema.ResourceData, m interface{}) diag.Diagnostics {
-	meta := m.(ProviderMetadata)
+	meta := resourceMeta(m, r)
 	client := meta.Client
 	src, dest, _ := SplitDestinationFilterId(r.Id())
 
@@ -198,15 +207,16 @@ func resourceSegmentDestinationFilterUpdate(ctx context.Context, r *schema.Resou
 		return d
 	}
 
-	if _, err := client.UpdateDestinationFilter(src, dest, filter); err != nil {
-		return diag.FromErr(err)
+	if _, err := client.UpdateDestinationFilter(ctx, src, dest, filter); err != nil {
+		path := parentPathIfNotFound(err, cty.GetAttrPath(keyFilterDestination), cty.GetAttrPath(keyFilterCondition))
+		return apiErrorDiag(err, "update destination filter "+r.Id(), path)
 	}
 
 	return resourceSegmentDestinationFilterRead(ctx, r, m)
 }
 
 func resourceSegmentDestinationFilterCreate(ctx context.Context, r *schema.ResourceData, m interface{}) diag.Diagnostics {
-	meta := m.(ProviderMetadata)
+	meta := resourceMeta(m, r)
 	client := meta.Client
 	destinationId := r.Get(keyFilterDestination).(string)
 	srcName, dstName := destinationIdToSourceAndDest(destinationId)
@@ -216,25 +226,31 @@ func resourceSegmentDestinationFilterCreate(ctx context.Context, r *schema.Resou
 		return d
 	}
 
-	created, err := client.CreateDestinationFilter(srcName, dstName, f)
+	created, err := client.CreateDestinationFilter(ctx, srcName, dstName, f)
 	if err != nil {
-		return diag.FromErr(err)
+		path := parentPathIfNotFound(err, cty.GetAttrPath(keyFilterDestination), cty.GetAttrPath(keyFilterCondition))
+		return apiErrorDiag(err, "create a filter for destination "+destinationId, path)
 	}
 
 	_, id := path.Split(created.Name)
 	r.SetId(destinationFilterResourceId(srcName, dstName, id))
 
-	return resourceSegmentDestinationFilterRead(ctx, r, m)
+	var rollback utils.Rollback
+	rollback.Add("Destination filter "+r.Id(), func(ctx context.Context) error {
+		return client.DeleteDestinationFilter(ctx, srcName, dstName, id)
+	})
+
+	return rollback.OnError(ctx, r, resourceSegmentDestinationFilterRead(ctx, r, m))
 }
 
-func resourceSegmentDestinationFilterDelete(_ context.Context, r *schema.ResourceData, m interface{}) diag.Diagnostics {
-	meta := m.(ProviderMetadata)
+func resourceSegmentDestinationFilterDelete(ctx context.Context, r *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := resourceMeta(m, r)
 	client := meta.Client
 	srcName, dstName, id := SplitDestinationFilterId(r.Id())
 
-	err := client.DeleteDestinationFilter(srcName, dstName, id)
+	err := client.DeleteDestinationFilter(ctx, srcName, dstName, id)
 	if err != nil {
-		return diag.FromErr(err)
+		return apiErrorDiag(err, "delete destination filter "+r.Id(), cty.GetAttrPath(keyFilterDestination))
 	}
 
 	return nil
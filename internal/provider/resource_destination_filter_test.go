@@ -1,6 +1,7 @@
 package provider_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
@@ -11,7 +12,9 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/uswitch/segment-config-go/segment"
+	"github.com/uswitch/terraform-provider-segment/internal/fake"
 	provider "github.com/uswitch/terraform-provider-segment/internal/provider"
 )
 
@@ -141,6 +144,81 @@ func TestAccDestinationFilter_actions(t *testing.T) {
 	})
 }
 
+func TestResourceDestinationFilter_lifecycle(t *testing.T) {
+	ctx := context.Background()
+	ws := fake.NewWorkspace(unitTestWorkspace)
+	_, err := ws.CreateSource(ctx, "my-source", "catalog/sources/javascript")
+	require.NoError(t, err)
+	_, err = ws.CreateDestination(ctx, "my-source", "rtb-house", "UNSPECIFIED", true, nil)
+	require.NoError(t, err)
+
+	r := newUnitTestResource(t, "segment_destination_filter", ws)
+	config := map[string]interface{}{
+		"destination": "my-source/rtb-house",
+		"title":       "Foo",
+		"description": "Bar",
+		"condition":   "context.castPermissions.marketing = false",
+		"enabled":     true,
+		"actions": []interface{}{map[string]interface{}{
+			"drop": []interface{}{map[string]interface{}{}},
+		}},
+	}
+
+	// Create
+	require.Empty(t, r.apply(config))
+	src, dest, filterID := provider.SplitDestinationFilterId(r.id())
+	assert.Equal(t, "my-source", src)
+	assert.Equal(t, "rtb-house", dest)
+	assert.Equal(t, "1", r.attr("actions.0.drop.#"))
+
+	// Update
+	config["title"] = "Foo1"
+	config["actions"] = []interface{}{map[string]interface{}{
+		"sample": []interface{}{map[string]interface{}{"percent": 0.5, "path": "userId"}},
+	}}
+	require.Empty(t, r.apply(config))
+	filter, err := ws.GetDestinationFilter(ctx, src, dest, filterID)
+	require.NoError(t, err)
+	assert.Equal(t, "Foo1", filter.Title)
+	require.Len(t, filter.Actions, 1)
+	assert.Equal(t, segment.DestinationFilterActionTypeSampling, filter.Actions[0].ActionType())
+
+	// Read
+	require.Empty(t, r.refresh())
+	assert.Equal(t, "Foo1", r.attr("title"))
+
+	// Delete
+	require.Empty(t, r.destroy())
+	_, err = ws.GetDestinationFilter(ctx, src, dest, filterID)
+	assert.Error(t, err)
+}
+
+func TestResourceDestinationFilter_deletedOutsideTerraform(t *testing.T) {
+	ctx := context.Background()
+	ws := fake.NewWorkspace(unitTestWorkspace)
+	_, err := ws.CreateSource(ctx, "my-source", "catalog/sources/javascript")
+	require.NoError(t, err)
+	_, err = ws.CreateDestination(ctx, "my-source", "rtb-house", "UNSPECIFIED", true, nil)
+	require.NoError(t, err)
+
+	r := newUnitTestResource(t, "segment_destination_filter", ws)
+	config := map[string]interface{}{
+		"destination": "my-source/rtb-house",
+		"title":       "Foo",
+		"condition":   "context.castPermissions.marketing = false",
+		"actions": []interface{}{map[string]interface{}{
+			"drop": []interface{}{map[string]interface{}{}},
+		}},
+	}
+	require.Empty(t, r.apply(config))
+	// Deleting the destination deletes its filters
+	require.NoError(t, ws.DeleteDestination(ctx, "my-source", "rtb-house"))
+
+	require.Empty(t, r.refresh())
+	assert.Empty(t, r.id(), "the filter should be removed from the state")
+	assert.NotNil(t, r.plan(config), "the filter should be recreated")
+}
+
 // Assertions
 
 func testAccDestinationFilterExists(t *testing.T, filterResName string, filter *segment.DestinationFilter) func(s *terraform.State) error {
@@ -150,7 +228,7 @@ func testAccDestinationFilterExists(t *testing.T, filterResName string, filter *
 		sourceName, destinationName, filterId := provider.SplitDestinationFilterId(filterState.Primary.ID)
 		log.Printf("[INFO] Getting filter %s/%s/%s", sourceName, destinationName, filterId)
 
-		f, err := client.GetDestinationFilter(sourceName, destinationName, filterId)
+		f, err := client.GetDestinationFilter(context.Background(), sourceName, destinationName, filterId)
 		if err != nil {
 			return err
 		}
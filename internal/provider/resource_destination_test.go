@@ -0,0 +1,300 @@
+package provider_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uswitch/terraform-provider-segment/internal/fake"
+)
+
+func TestResourceDestination_lifecycle(t *testing.T) {
+	ctx := context.Background()
+	ws := fake.NewWorkspace(unitTestWorkspace)
+	_, err := ws.CreateSource(ctx, "my-source", "catalog/sources/javascript")
+	require.NoError(t, err)
+
+	r := newUnitTestResource(t, "segment_destination", ws)
+	config := map[string]interface{}{
+		"source":          "my-source",
+		"name":            "google-analytics",
+		"enabled":         true,
+		"connection_mode": "CLOUD",
+		"config": map[string]interface{}{
+			"trackingId": `{"type":"string","value":"UA-123"}`,
+		},
+	}
+
+	// Create
+	require.Empty(t, r.apply(config))
+	assert.Equal(t, "my-source/google-analytics", r.id())
+	dest, err := ws.GetDestination(ctx, "my-source", "google-analytics")
+	require.NoError(t, err)
+	assert.True(t, dest.Enabled)
+	require.Len(t, dest.Configs, 1)
+	assert.Equal(t, "workspaces/unit-test/sources/my-source/destinations/google-analytics/config/trackingId", dest.Configs[0].Name)
+
+	// Update
+	config["enabled"] = false
+	require.Empty(t, r.apply(config))
+	assert.Equal(t, "false", r.attr("enabled"))
+	dest, err = ws.GetDestination(ctx, "my-source", "google-analytics")
+	require.NoError(t, err)
+	assert.False(t, dest.Enabled)
+
+	// Read
+	require.Empty(t, r.refresh())
+	assert.Equal(t, "CLOUD", r.attr("connection_mode"))
+	assert.Nil(t, r.plan(config))
+
+	// Delete
+	require.Empty(t, r.destroy())
+	_, err = ws.GetDestination(ctx, "my-source", "google-analytics")
+	assert.Error(t, err)
+}
+
+func TestResourceDestination_deletedOutsideTerraform(t *testing.T) {
+	ctx := context.Background()
+	ws := fake.NewWorkspace(unitTestWorkspace)
+	_, err := ws.CreateSource(ctx, "my-source", "catalog/sources/javascript")
+	require.NoError(t, err)
+
+	r := newUnitTestResource(t, "segment_destination", ws)
+	config := map[string]interface{}{
+		"source":          "my-source",
+		"name":            "google-analytics",
+		"enabled":         true,
+		"connection_mode": "CLOUD",
+		"config":          map[string]interface{}{},
+	}
+	require.Empty(t, r.apply(config))
+	require.NoError(t, ws.DeleteDestination(ctx, "my-source", "google-analytics"))
+
+	require.Empty(t, r.refresh())
+	assert.Empty(t, r.id(), "the destination should be removed from the state")
+	assert.NotNil(t, r.plan(config), "the destination should be recreated")
+}
+
+func TestResourceDestination_invalidConfig(t *testing.T) {
+	ctx := context.Background()
+	ws := fake.NewWorkspace(unitTestWorkspace)
+	_, err := ws.CreateSource(ctx, "my-source", "catalog/sources/javascript")
+	require.NoError(t, err)
+
+	r := newUnitTestResource(t, "segment_destination", ws)
+	d := r.apply(map[string]interface{}{
+		"source":  "my-source",
+		"name":    "google-analytics",
+		"enabled": true,
+		"config": map[string]interface{}{
+			"trackingId": `{"type":"string","value":"UA-123"}`,
+			"siteSpeed":  `{"type":"select","value":""}`,
+		},
+	})
+
+	require.Len(t, d, 1)
+	assert.Equal(t, "Invalid destination config", d[0].Summary)
+	assert.Contains(t, d[0].Detail, "config key siteSpeed")
+	assert.Equal(t, cty.GetAttrPath("config").IndexString("siteSpeed"), d[0].AttributePath)
+}
+
+func TestResourceDestination_missingSource(t *testing.T) {
+	ws := fake.NewWorkspace(unitTestWorkspace)
+
+	r := newUnitTestResource(t, "segment_destination", ws)
+	d := r.apply(map[string]interface{}{
+		"source":  "my-source",
+		"name":    "google-analytics",
+		"enabled": true,
+		"config":  map[string]interface{}{},
+	})
+
+	require.Len(t, d, 1)
+	assert.Equal(t, "Segment resource not found", d[0].Summary)
+	assert.Equal(t, cty.GetAttrPath("source"), d[0].AttributePath)
+}
+
+func TestResourceDestination_disableOnDestroy(t *testing.T) {
+	ctx := context.Background()
+	ws := fake.NewWorkspace(unitTestWorkspace)
+	_, err := ws.CreateSource(ctx, "my-source", "catalog/sources/javascript")
+	require.NoError(t, err)
+
+	config := map[string]interface{}{
+		"source":          "my-source",
+		"name":            "google-analytics",
+		"enabled":         true,
+		"connection_mode": "CLOUD",
+		"on_destroy":      "disable",
+		"config": map[string]interface{}{
+			"trackingId": `{"type":"string","value":"UA-123"}`,
+		},
+	}
+	r := newUnitTestResource(t, "segment_destination", ws)
+	require.Empty(t, r.apply(config))
+
+	// Destroying disables the destination
+	require.Empty(t, r.destroy())
+	assert.Zero(t, ws.CallCount("DeleteDestination"))
+	dest, err := ws.GetDestination(ctx, "my-source", "google-analytics")
+	require.NoError(t, err)
+	assert.False(t, dest.Enabled)
+	require.Len(t, dest.Configs, 1, "the config should be kept")
+
+	// Creating it again adopts and re-enables it
+	recreated := newUnitTestResource(t, "segment_destination", ws)
+	require.Empty(t, recreated.apply(config))
+	assert.Equal(t, "my-source/google-analytics", recreated.id())
+	assert.Equal(t, "true", recreated.attr("enabled"))
+	assert.Equal(t, 1, ws.CallCount("CreateDestination"))
+	dest, err = ws.GetDestination(ctx, "my-source", "google-analytics")
+	require.NoError(t, err)
+	assert.True(t, dest.Enabled)
+	assert.Nil(t, recreated.plan(config))
+
+	// Switching back to deletion
+	config["on_destroy"] = "delete"
+	require.Empty(t, recreated.apply(config))
+	require.Empty(t, recreated.destroy())
+	_, err = ws.GetDestination(ctx, "my-source", "google-analytics")
+	assert.Error(t, err)
+}
+
+func TestResourceDestination_doesNotAdoptEnabledDestinations(t *testing.T) {
+	ctx := context.Background()
+	ws := fake.NewWorkspace(unitTestWorkspace)
+	_, err := ws.CreateSource(ctx, "my-source", "catalog/sources/javascript")
+	require.NoError(t, err)
+	_, err = ws.CreateDestination(ctx, "my-source", "google-analytics", "CLOUD", true, nil)
+	require.NoError(t, err)
+
+	r := newUnitTestResource(t, "segment_destination", ws)
+	d := r.apply(map[string]interface{}{
+		"source":  "my-source",
+		"name":    "google-analytics",
+		"enabled": true,
+		"config":  map[string]interface{}{},
+	})
+
+	require.True(t, d.HasError())
+	assert.Contains(t, d[0].Detail, "already exists")
+	assert.Zero(t, ws.CallCount("UpdateDestination"))
+}
+
+func TestResourceDestination_onlyAdoptsWithDisableOnDestroy(t *testing.T) {
+	ctx := context.Background()
+	ws := fake.NewWorkspace(unitTestWorkspace)
+	_, err := ws.CreateSource(ctx, "my-source", "catalog/sources/javascript")
+	require.NoError(t, err)
+	_, err = ws.CreateDestination(ctx, "my-source", "google-analytics", "CLOUD", false, nil)
+	require.NoError(t, err)
+
+	r := newUnitTestResource(t, "segment_destination", ws)
+	d := r.apply(map[string]interface{}{
+		"source":  "my-source",
+		"name":    "google-analytics",
+		"enabled": true,
+		"config":  map[string]interface{}{},
+	})
+
+	require.True(t, d.HasError())
+	assert.Contains(t, d[0].Detail, "already exists")
+	assert.Zero(t, ws.CallCount("GetDestination"), "creating a destination shouldn't look for one to adopt")
+	assert.Zero(t, ws.CallCount("UpdateDestination"))
+}
+
+func TestResourceDestination_doesNotAdoptOtherConnectionModes(t *testing.T) {
+	ctx := context.Background()
+	ws := fake.NewWorkspace(unitTestWorkspace)
+	_, err := ws.CreateSource(ctx, "my-source", "catalog/sources/javascript")
+	require.NoError(t, err)
+	_, err = ws.CreateDestination(ctx, "my-source", "google-analytics", "DEVICE", false, nil)
+	require.NoError(t, err)
+
+	r := newUnitTestResource(t, "segment_destination", ws)
+	d := r.apply(map[string]interface{}{
+		"source":          "my-source",
+		"name":            "google-analytics",
+		"enabled":         true,
+		"connection_mode": "CLOUD",
+		"on_destroy":      "disable",
+		"config":          map[string]interface{}{},
+	})
+
+	require.Len(t, d, 1)
+	assert.Equal(t, "Disabled destination with another connection mode", d[0].Summary)
+	assert.Equal(t, cty.GetAttrPath("connection_mode"), d[0].AttributePath)
+	assert.Empty(t, r.id())
+	dest, err := ws.GetDestination(ctx, "my-source", "google-analytics")
+	require.NoError(t, err)
+	assert.False(t, dest.Enabled)
+}
+
+func TestResourceDestination_disableOnDestroyDeletedOutsideTerraform(t *testing.T) {
+	ctx := context.Background()
+	ws := fake.NewWorkspace(unitTestWorkspace)
+	_, err := ws.CreateSource(ctx, "my-source", "catalog/sources/javascript")
+	require.NoError(t, err)
+
+	r := newUnitTestResource(t, "segment_destination", ws)
+	require.Empty(t, r.apply(map[string]interface{}{
+		"source":     "my-source",
+		"name":       "google-analytics",
+		"enabled":    true,
+		"on_destroy": "disable",
+		"config":     map[string]interface{}{},
+	}))
+	require.NoError(t, ws.DeleteDestination(ctx, "my-source", "google-analytics"))
+
+	assert.Empty(t, r.destroy())
+}
+
+func TestResourceDestination_importDefaultsOnDestroy(t *testing.T) {
+	ctx := context.Background()
+	ws := fake.NewWorkspace(unitTestWorkspace)
+	_, err := ws.CreateSource(ctx, "my-source", "catalog/sources/javascript")
+	require.NoError(t, err)
+	_, err = ws.CreateDestination(ctx, "my-source", "google-analytics", "CLOUD", true, nil)
+	require.NoError(t, err)
+
+	r := newUnitTestResource(t, "segment_destination", ws)
+	require.Empty(t, r.importState("my-source/google-analytics"))
+
+	assert.Equal(t, "delete", r.attr("on_destroy"))
+	assert.Nil(t, r.plan(map[string]interface{}{
+		"source":          "my-source",
+		"name":            "google-analytics",
+		"enabled":         true,
+		"connection_mode": "CLOUD",
+		"config":          map[string]interface{}{},
+	}))
+}
+
+func TestResourceDestination_readFailureAfterAdoptionDisablesAgain(t *testing.T) {
+	ctx := context.Background()
+	ws := fake.NewWorkspace(unitTestWorkspace)
+	_, err := ws.CreateSource(ctx, "my-source", "catalog/sources/javascript")
+	require.NoError(t, err)
+	_, err = ws.CreateDestination(ctx, "my-source", "google-analytics", "CLOUD", false, nil)
+	require.NoError(t, err)
+	// The first read checks for a disabled destination to adopt
+	ws.InjectFault(fake.Fault{Method: "GetDestination", Code: http.StatusBadGateway, Times: 1, After: 1})
+
+	r := newUnitTestResource(t, "segment_destination", ws)
+	d := r.apply(map[string]interface{}{
+		"source":     "my-source",
+		"name":       "google-analytics",
+		"enabled":    true,
+		"on_destroy": "disable",
+		"config":     map[string]interface{}{},
+	})
+
+	require.Len(t, d, 1)
+	assert.Empty(t, r.id())
+	dest, err := ws.GetDestination(ctx, "my-source", "google-analytics")
+	require.NoError(t, err, "the adopted destination shouldn't be deleted")
+	assert.False(t, dest.Enabled)
+}
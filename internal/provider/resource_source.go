@@ -5,8 +5,9 @@ import (
 	"fmt"
 	"log"
 	"reflect"
-	"time"
 
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
@@ -15,12 +16,14 @@ import (
 )
 
 const (
-	keySource             = "source_name"
-	keyCatalog            = "catalog_name"
-	keyTrackingPlan       = "tracking_plan"
-	keySchemaConfig       = "schema_config"
-	configApiInitialDelay = 75 * time.Millisecond
-	configApiMaxRetries   = 30
+	keySource       = "source_name"
+	keyCatalog      = "catalog_name"
+	keyTrackingPlan = "tracking_plan"
+	keySchemaConfig = "schema_config"
+	keyWriteKeys    = "write_keys"
+
+	keySourceDisplayName = "display_name"
+	keySourceEnabled     = "enabled"
 )
 
 var (
@@ -41,13 +44,13 @@ var (
 		CommonIdentifyEventOnViolations:     segment.Allow,
 		CommonGroupEventOnViolations:        segment.Allow,
 	}
-	cache TrackingPlansConnectionsCache = map[string]string{}
 )
 
 func resourceSegmentSource() *schema.Resource {
-	return &schema.Resource{
+	return withDeletionProtection("source", &schema.Resource{
 		Description: "A source connection on Segment. More information on sources and how to use them can be found in the [Segment Sources documentation](https://segment.com/docs/connections/sources/).",
 		Schema: map[string]*schema.Schema{
+			keyWorkspace: workspaceSchema("source"),
 			keySource: {
 				Description: "The name of the source",
 				Type:        schema.TypeString,
@@ -60,6 +63,16 @@ func resourceSegmentSource() *schema.Resource {
 				Required:    true,
 				ForceNew:    true,
 			},
+			keySourceDisplayName: {
+				Description: "The name of the source in the Segment UI, which is the `source_name` unless renamed in the UI. Kept as is when the Config API doesn't return it. *(Set by Segment).*",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			keySourceEnabled: {
+				Description: "Whether the source is enabled. Segment drops the events sent to disabled sources. Kept as is when the Config API doesn't return it. *(Set by Segment).*",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
 			keyTrackingPlan: {
 				Description: "The ID of the associated tracking plan.",
 				Type:        schema.TypeString,
@@ -157,27 +170,41 @@ func resourceSegmentSource() *schema.Resource {
 					},
 				},
 			},
+			keyWriteKeys: {
+				Description: "The write keys of the source, which clients send events with. *(Set by Segment).*",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Sensitive:   true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
 		},
-		CreateContext: resourceSegmentSourceCreate,
-		ReadContext:   resourceSegmentSourceRead,
-		DeleteContext: resourceSegmentSourceDelete,
-		UpdateContext: resourceSegmentSourceUpdate,
+		CreateContext: operation("create segment_source", resourceSegmentSourceCreate),
+		ReadContext:   operation("read segment_source", resourceSegmentSourceRead),
+		DeleteContext: operation("delete segment_source", resourceSegmentSourceDelete),
+		UpdateContext: operation("update segment_source", resourceSegmentSourceUpdate),
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: importWithWorkspace(1),
 		},
-	}
+		Timeouts: resourceTimeouts(),
+	})
 }
 
-func resourceSegmentSourceRead(_ context.Context, r *schema.ResourceData, m interface{}) diag.Diagnostics {
-	meta := m.(ProviderMetadata)
+func resourceSegmentSourceRead(ctx context.Context, r *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := resourceMeta(m, r)
 	client := meta.Client
 	id := r.Id()
 
-	rawSource, err := utils.WithBackoff(func() (interface{}, error) { return client.GetSource(id) }, configApiInitialDelay, configApiMaxRetries)
+	s, err := client.GetSource(ctx, id)
+	if utils.IsNotFound(err) && !r.IsNewResource() {
+		tflog.Warn(ctx, "Source not found, removing it from the state", map[string]interface{}{"id": id})
+		r.SetId("")
+		return nil
+	}
 	if err != nil {
-		return diag.FromErr(err)
+		return apiErrorDiag(err, "read source "+id, cty.GetAttrPath(keySource))
 	}
-	s := rawSource.(segment.Source)
 
 	if err = r.Set(keyCatalog, s.CatalogName); err != nil {
 		return diag.FromErr(err)
@@ -187,12 +214,27 @@ func resourceSegmentSourceRead(_ context.Context, r *schema.ResourceData, m inte
 		return diag.FromErr(err)
 	}
 
-	tpID, d := initTrackingPlan(r.Get(keyTrackingPlan).(string), id, client)
+	if err = r.Set(keyWriteKeys, s.WriteKeys); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// Responses without them leave the state as is
+	if s.DisplayName != nil {
+		if err = r.Set(keySourceDisplayName, *s.DisplayName); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if s.Enabled != nil {
+		if err = r.Set(keySourceEnabled, *s.Enabled); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	tpID, d := initTrackingPlan(ctx, r.Get(keyTrackingPlan).(string), id, meta)
 	if d != nil {
-		log.Println("[WARN] Error initialising tracking plan")
 		return *d
 	}
-	log.Println("[INFO] Done initialising tracking plan")
 
 	hasTrackingPlanSet := tpID != ""
 	if hasTrackingPlanSet {
@@ -200,66 +242,66 @@ func resourceSegmentSourceRead(_ context.Context, r *schema.ResourceData, m inte
 			return diag.FromErr(err)
 		}
 
-		config, err := client.GetSourceConfig(id)
+		config, err := client.GetSourceConfig(ctx, id)
 		if err != nil {
-			return diag.FromErr(err)
+			return apiErrorDiag(err, "read the schema config of source "+id, cty.GetAttrPath(keySchemaConfig))
 		}
 		if err = r.Set(keySchemaConfig, encodeSourceConfig(config)); err != nil {
 			return diag.FromErr(err)
 		}
 	}
 
-	log.Printf("[INFO] Done reading source %s", id)
+	tflog.Debug(ctx, "Read source", map[string]interface{}{"id": id, "tracking_plan": tpID})
 	return nil
 }
 
 func resourceSegmentSourceCreate(ctx context.Context, r *schema.ResourceData, m interface{}) diag.Diagnostics {
-	meta := m.(ProviderMetadata)
+	meta := resourceMeta(m, r)
 	client := meta.Client
 	srcName := r.Get(keySource).(string)
 	catName := r.Get(keyCatalog).(string)
 
-	log.Println("[INFO] Creating source " + srcName)
-	if _, err := client.CreateSource(srcName, catName); err != nil {
-		return diag.FromErr(err)
+	tflog.Info(ctx, "Creating source", map[string]interface{}{"id": srcName, "catalog": catName})
+	if _, err := client.CreateSource(ctx, srcName, catName); err != nil {
+		return catalogErrorDiag(err, "create source "+srcName, catName)
 	}
 
-	revertCreation := func(d diag.Diagnostics) diag.Diagnostics {
-		log.Println("[INFO] An error occured, reverting source creation")
-		if err := client.DeleteSource(srcName); err != nil {
-			d = append(d, diag.Diagnostic{
-				Severity: diag.Warning,
-				Summary:  "Lingering Segment resources",
-				Detail:   fmt.Sprintf("Source %s could not be cleaned up because of %s. Check Segment for manual cleanup", srcName, err),
-			})
+	var rollback utils.Rollback
+	rollback.Add("Source "+srcName, func(ctx context.Context) error {
+		if err := client.DeleteSource(ctx, srcName); err != nil {
+			return err
 		}
-		return d
-	}
-
-	log.Println("[INFO] Linking source to tracking plan " + r.Get("tracking_plan").(string))
-	if d := updateTrackingPlan(r, client); d != nil {
-		return revertCreation(*d)
+		meta.TrackingPlansConnections.disconnect(srcName)
+		return nil
+	})
+
+	tflog.Info(ctx, "Connecting the source to its tracking plan", map[string]interface{}{
+		"id":            srcName,
+		"tracking_plan": r.Get(keyTrackingPlan).(string),
+	})
+	if d := updateTrackingPlan(ctx, r, meta); d != nil {
+		return rollback.OnError(ctx, r, *d)
 	}
 
-	if d := updateSchemaConfig(r, client); d != nil {
-		return revertCreation(*d)
+	if d := updateSchemaConfig(ctx, r, client); d != nil {
+		return rollback.OnError(ctx, r, *d)
 	}
 
 	r.SetId(srcName)
 
-	return resourceSegmentSourceRead(ctx, r, m)
+	return rollback.OnError(ctx, r, resourceSegmentSourceRead(ctx, r, m))
 }
 
 func resourceSegmentSourceUpdate(ctx context.Context, r *schema.ResourceData, m interface{}) diag.Diagnostics {
-	meta := m.(ProviderMetadata)
+	meta := resourceMeta(m, r)
 	client := meta.Client
 	srcName := r.Get(keySource).(string)
 
-	if d := updateTrackingPlan(r, client); d != nil {
+	if d := updateTrackingPlan(ctx, r, meta); d != nil {
 		return *d
 	}
 
-	if d := updateSchemaConfig(r, client); d != nil {
+	if d := updateSchemaConfig(ctx, r, client); d != nil {
 		return *d
 	}
 
@@ -268,15 +310,20 @@ func resourceSegmentSourceUpdate(ctx context.Context, r *schema.ResourceData, m
 	return resourceSegmentSourceRead(ctx, r, m)
 }
 
-func resourceSegmentSourceDelete(_ context.Context, r *schema.ResourceData, m interface{}) diag.Diagnostics {
-	meta := m.(ProviderMetadata)
+func resourceSegmentSourceDelete(ctx context.Context, r *schema.ResourceData, m interface{}) diag.Diagnostics {
+	meta := resourceMeta(m, r)
 	client := meta.Client
 	id := r.Id()
 
-	err := client.DeleteSource(id)
+	if d := refuseProtectedDeletion(r, "delete source "+id); d != nil {
+		return d
+	}
+
+	err := client.DeleteSource(ctx, id)
 	if err != nil {
-		return diag.FromErr(err)
+		return apiErrorDiag(err, "delete source "+id, cty.GetAttrPath(keySource))
 	}
+	meta.TrackingPlansConnections.disconnect(id)
 
 	return nil
 }
@@ -347,7 +394,7 @@ func getSchemaConfigOrDefault(r *schema.ResourceData, dst *segment.SourceConfig)
 	return nil
 }
 
-func updateSchemaConfig(r *schema.ResourceData, client segment.Client) *diag.Diagnostics {
+func updateSchemaConfig(ctx context.Context, r *schema.ResourceData, client SegmentAPI) *diag.Diagnostics {
 	if !r.HasChange(keySchemaConfig) {
 		return nil
 	}
@@ -362,14 +409,17 @@ func updateSchemaConfig(r *schema.ResourceData, client segment.Client) *diag.Dia
 			return d
 		}
 
-		log.Printf("[INFO] Updating schema config for %s <-> %s", srcName, tpID)
-		_, err := client.UpdateSourceConfig(srcName, config)
+		tflog.Info(ctx, "Updating the schema config of the source", map[string]interface{}{"id": srcName, "tracking_plan": tpID})
+		_, err := client.UpdateSourceConfig(ctx, srcName, config)
 		if err != nil {
-			return utils.DiagFromErrPtr(err)
+			d := apiErrorDiag(err, "update the schema config of source "+srcName, cty.GetAttrPath(keySchemaConfig))
+			return &d
 		}
 	} else {
 		// We wipe the previous config out of the state as there's no more tracking plan attached
-		r.Set(keySchemaConfig, nil)
+		if err := r.Set(keySchemaConfig, nil); err != nil {
+			return utils.DiagFromErrPtr(err)
+		}
 	}
 
 	return nil
@@ -377,46 +427,50 @@ func updateSchemaConfig(r *schema.ResourceData, client segment.Client) *diag.Dia
 
 // Tracking Plans
 
-func updateTrackingPlan(r *schema.ResourceData, client segment.Client) *diag.Diagnostics {
+func updateTrackingPlan(ctx context.Context, r *schema.ResourceData, meta ProviderMetadata) *diag.Diagnostics {
+	client := meta.Client
 	srcName := r.Get(keySource).(string)
 
 	if old, new := r.GetChange(keyTrackingPlan); old != new {
 		if old != "" {
-			if err := client.DeleteTrackingPlanSourceConnection(old.(string), srcName); err != nil {
-				return utils.DiagFromErrPtr(err)
+			if err := client.DeleteTrackingPlanSourceConnection(ctx, old.(string), srcName); err != nil {
+				d := protocolsErrorDiag(err, fmt.Sprintf("disconnect source %s from tracking plan %s", srcName, old), cty.GetAttrPath(keyTrackingPlan))
+				return &d
 			}
+			meta.TrackingPlansConnections.disconnect(srcName)
 		}
 
 		if new != "" {
-			if err := client.CreateTrackingPlanSourceConnection(new.(string), srcName); err != nil {
-				return utils.DiagFromErrPtr(err)
+			if err := client.CreateTrackingPlanSourceConnection(ctx, new.(string), srcName); err != nil {
+				d := protocolsErrorDiag(err, fmt.Sprintf("connect source %s to tracking plan %s", srcName, new), cty.GetAttrPath(keyTrackingPlan))
+				return &d
 			}
+			meta.TrackingPlansConnections.connect(srcName, new.(string))
 		}
 	}
 	return nil
 }
 
-func initTrackingPlan(tpID string, source string, client segment.Client) (string, *diag.Diagnostics) {
+func initTrackingPlan(ctx context.Context, tpID string, source string, meta ProviderMetadata) (string, *diag.Diagnostics) {
 	if tpID != "" {
 		// We first try to match the tracking plan specified in the config to avoid expensive calls
-		if d := assertTrackingPlanConnected(tpID, source, client); d != nil {
-			return findTrackingPlanSourceConnection(source, client)
+		if d := assertTrackingPlanConnected(ctx, tpID, source, meta.Client); d != nil {
+			return findTrackingPlanSourceConnection(ctx, source, meta)
 		}
 
 		return tpID, nil
 	} else {
 		// When the tracking plan is not specified, we search for it, so we can import existing sources
-		return findTrackingPlanSourceConnection(source, client)
+		return findTrackingPlanSourceConnection(ctx, source, meta)
 	}
 }
 
 // assertTrackingPlanConnected verifies a tracking plan and a source are connected and fails otherwise
-func assertTrackingPlanConnected(trackingPlan string, src string, client segment.Client) *diag.Diagnostics {
-	rawSources, err := utils.WithBackoff(func() (interface{}, error) { return client.ListTrackingPlanSources(trackingPlan) }, configApiInitialDelay, configApiMaxRetries)
+func assertTrackingPlanConnected(ctx context.Context, trackingPlan string, src string, client SegmentAPI) *diag.Diagnostics {
+	sources, err := client.ListTrackingPlanSources(ctx, trackingPlan)
 	if err != nil {
 		return utils.DiagFromErrPtr(fmt.Errorf("invalid tracking plan ID %s: %w", trackingPlan, err))
 	}
-	sources := rawSources.([]segment.TrackingPlanSourceConnection)
 
 	for _, s := range sources {
 		if utils.PathToName(s.Source) == src {
@@ -429,23 +483,22 @@ func assertTrackingPlanConnected(trackingPlan string, src string, client segment
 }
 
 // findTrackingPlanSourceConnection finds the connected tracking plan, or "" if the source is not connected
-func findTrackingPlanSourceConnection(source string, client segment.Client) (string, *diag.Diagnostics) {
-	if len(cache) > 0 {
-		return cache.find(source), nil
-	}
-
-	if err := cache.init(client); err != nil {
-		return "", utils.DiagFromErrPtr(err)
+func findTrackingPlanSourceConnection(ctx context.Context, source string, meta ProviderMetadata) (string, *diag.Diagnostics) {
+	tpID, err := meta.TrackingPlansConnections.find(ctx, meta.Client, source)
+	if err != nil {
+		d := protocolsErrorDiag(err, "find the tracking plan of source "+source, cty.GetAttrPath(keyTrackingPlan))
+		return "", &d
 	}
 
-	return cache.find(source), nil
+	return tpID, nil
 }
 
 // suppressSchemaConfigDiff hides changes to schema config when it is not specified explicitely but using the default one
 func suppressSchemaConfigDiff(k, old, new string, d *schema.ResourceData) bool {
 	var config segment.SourceConfig
 	if err := decodeSourceConfig(d.Get(keySchemaConfig).([]interface{})[0], &config); err != nil {
-		log.Printf("[WARNING]: Problem when suppressing diff for %s: %s => %s: %v", k, old, new, err)
+		// Diff suppression functions have no context to log with tflog
+		log.Printf("[WARN] Problem when suppressing diff for %s: %s => %s: %v", k, old, new, err)
 		return false
 	}
 
@@ -453,51 +506,3 @@ func suppressSchemaConfigDiff(k, old, new string, d *schema.ResourceData) bool {
 	isUsingDefaultConfig := reflect.DeepEqual(config, defaultSourceConfig)
 	return noChange && isUsingDefaultConfig
 }
-
-// Tracking plans connections cache
-
-type TrackingPlansConnectionsCache map[string]string
-
-func (cache TrackingPlansConnectionsCache) find(source string) string {
-	log.Printf("[INFO] looking for %s in cache", utils.PathToName(source))
-	if tp := cache[utils.PathToName(source)]; tp != "" {
-		log.Printf("[INFO] Tracking plan cache hit for %s <-> %s", source, tp)
-		return tp
-	}
-
-	return ""
-}
-
-func (cache TrackingPlansConnectionsCache) add(connections []segment.TrackingPlanSourceConnection) {
-	if len(connections) < 1 {
-		return
-	}
-
-	log.Printf("[INFO] Caching %d connections for %s", len(connections), connections[0].TrackingPlanId)
-	for _, currSrc := range connections {
-		source := utils.PathToName(currSrc.Source)
-		cache[source] = currSrc.TrackingPlanId
-	}
-
-	log.Printf("[INFO] Cache has %d entries", len(cache))
-}
-
-func (cache TrackingPlansConnectionsCache) init(client segment.Client) error {
-	rawTps, err := utils.WithBackoff(func() (interface{}, error) { return client.ListTrackingPlans() }, configApiInitialDelay, configApiMaxRetries)
-	if err != nil {
-		return err
-	}
-	tps := rawTps.(segment.TrackingPlans)
-
-	for _, tp := range tps.TrackingPlans {
-		tpID := utils.PathToName(tp.Name)
-		rawSrcs, err := utils.WithBackoff(func() (interface{}, error) { return client.ListTrackingPlanSources(tpID) }, configApiInitialDelay, configApiMaxRetries)
-		if err != nil {
-			return err
-		}
-		srcs := rawSrcs.([]segment.TrackingPlanSourceConnection)
-		cache.add(srcs)
-	}
-
-	return nil
-}
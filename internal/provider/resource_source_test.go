@@ -0,0 +1,340 @@
+package provider_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uswitch/segment-config-go/segment"
+	"github.com/uswitch/terraform-provider-segment/internal/configapi"
+	"github.com/uswitch/terraform-provider-segment/internal/fake"
+	"github.com/uswitch/terraform-provider-segment/internal/provider"
+	"github.com/uswitch/terraform-provider-segment/internal/utils"
+)
+
+func TestResourceSource_lifecycle(t *testing.T) {
+	ctx := context.Background()
+	ws := fake.NewWorkspace(unitTestWorkspace)
+	tp, err := ws.CreateTrackingPlan(ctx, segment.TrackingPlan{DisplayName: "Plan"})
+	require.NoError(t, err)
+	tpID := utils.PathToName(tp.Name)
+
+	r := newUnitTestResource(t, "segment_source", ws)
+	config := map[string]interface{}{
+		"source_name":  "my-source",
+		"catalog_name": "catalog/sources/javascript",
+	}
+
+	// Create
+	require.Empty(t, r.apply(config))
+	assert.Equal(t, "my-source", r.id())
+	assert.Equal(t, "catalog/sources/javascript", r.attr("catalog_name"))
+	src, err := ws.GetSource(ctx, "my-source")
+	require.NoError(t, err)
+	assert.Equal(t, "workspaces/unit-test/sources/my-source", src.Name)
+	require.Len(t, src.WriteKeys, 1)
+	assert.Equal(t, "1", r.attr("write_keys.#"))
+	assert.Equal(t, src.WriteKeys[0], r.attr("write_keys.0"))
+
+	// Update
+	config["tracking_plan"] = tpID
+	require.Empty(t, r.apply(config))
+	assert.Equal(t, tpID, r.attr("tracking_plan"))
+	connections, err := ws.ListTrackingPlanSources(ctx, tpID)
+	require.NoError(t, err)
+	require.Len(t, connections, 1)
+	assert.Equal(t, "workspaces/unit-test/sources/my-source", connections[0].Source)
+
+	// Read
+	require.Empty(t, r.refresh())
+	assert.Nil(t, r.plan(config))
+
+	// Delete
+	require.Empty(t, r.destroy())
+	_, err = ws.GetSource(ctx, "my-source")
+	assert.Error(t, err)
+}
+
+func TestResourceSource_writeKeysAreSensitive(t *testing.T) {
+	writeKeys := provider.New().ResourcesMap["segment_source"].Schema["write_keys"]
+
+	assert.True(t, writeKeys.Sensitive)
+	assert.True(t, writeKeys.Computed)
+	assert.False(t, writeKeys.Optional)
+}
+
+func TestResourceSource_displayNameAndEnabledFollowSegment(t *testing.T) {
+	ws := fake.NewWorkspace(unitTestWorkspace)
+
+	r := newUnitTestResource(t, "segment_source", ws)
+	config := map[string]interface{}{
+		"source_name":  "my-source",
+		"catalog_name": "catalog/sources/javascript",
+	}
+	require.Empty(t, r.apply(config))
+	assert.Equal(t, "my-source", r.attr("display_name"))
+	assert.Equal(t, "true", r.attr("enabled"))
+
+	// Renaming and disabling the source in the Segment UI doesn't conflict with the config
+	require.NoError(t, ws.EditSource("my-source", "My Source", false))
+	require.Empty(t, r.refresh())
+	assert.Equal(t, "My Source", r.attr("display_name"))
+	assert.Equal(t, "false", r.attr("enabled"))
+	assert.Nil(t, r.plan(config))
+}
+
+// withoutSourceAttributes omits the display name of sources and whether they are enabled, as the responses of Get
+// Source documented for v1beta
+type withoutSourceAttributes struct {
+	*fake.Workspace
+}
+
+func (w withoutSourceAttributes) GetSource(ctx context.Context, srcName string) (configapi.Source, error) {
+	s, err := w.Workspace.GetSource(ctx, srcName)
+	s.DisplayName = nil
+	s.Enabled = nil
+
+	return s, err
+}
+
+func TestResourceSource_attributesMissingFromResponses(t *testing.T) {
+	ws := fake.NewWorkspace(unitTestWorkspace)
+
+	r := newUnitTestResource(t, "segment_source", ws)
+	config := map[string]interface{}{
+		"source_name":  "my-source",
+		"catalog_name": "catalog/sources/javascript",
+	}
+	require.Empty(t, r.apply(config))
+
+	r.meta.Client = withoutSourceAttributes{ws}
+	require.NoError(t, ws.EditSource("my-source", "My Source", false))
+	require.Empty(t, r.refresh())
+
+	assert.Equal(t, "my-source", r.attr("display_name"), "the state should be kept")
+	assert.Equal(t, "true", r.attr("enabled"), "the state should be kept")
+	assert.Nil(t, r.plan(config))
+}
+
+func TestResourceSource_createFailureCleansUp(t *testing.T) {
+	ctx := context.Background()
+	ws := fake.NewWorkspace(unitTestWorkspace)
+
+	r := newUnitTestResource(t, "segment_source", ws)
+	d := r.apply(map[string]interface{}{
+		"source_name":   "my-source",
+		"catalog_name":  "catalog/sources/javascript",
+		"tracking_plan": "rs_does_not_exist",
+	})
+
+	assert.True(t, d.HasError())
+	_, err := ws.GetSource(ctx, "my-source")
+	assert.Error(t, err, "the source should have been deleted after the failed creation")
+}
+
+func TestResourceSource_trackingPlanCacheFollowsConnections(t *testing.T) {
+	ctx := context.Background()
+	ws := fake.NewWorkspace(unitTestWorkspace)
+	tp1, err := ws.CreateTrackingPlan(ctx, segment.TrackingPlan{DisplayName: "Plan 1"})
+	require.NoError(t, err)
+	tp2, err := ws.CreateTrackingPlan(ctx, segment.TrackingPlan{DisplayName: "Plan 2"})
+	require.NoError(t, err)
+	_, err = ws.CreateSource(ctx, "my-source", "catalog/sources/javascript")
+	require.NoError(t, err)
+	require.NoError(t, ws.CreateTrackingPlanSourceConnection(ctx, utils.PathToName(tp1.Name), "my-source"))
+	meta := unitTestMeta(ws)
+
+	r := newUnitTestResourceWithMeta(t, "segment_source", meta)
+	require.Empty(t, r.importState("my-source"))
+	assert.Equal(t, utils.PathToName(tp1.Name), r.attr("tracking_plan"))
+
+	require.Empty(t, r.apply(map[string]interface{}{
+		"source_name":   "my-source",
+		"catalog_name":  "catalog/sources/javascript",
+		"tracking_plan": utils.PathToName(tp2.Name),
+	}))
+
+	imported := newUnitTestResourceWithMeta(t, "segment_source", meta)
+	require.Empty(t, imported.importState("my-source"))
+	assert.Equal(t, utils.PathToName(tp2.Name), imported.attr("tracking_plan"), "the cache should follow the new connection")
+	assert.Equal(t, 1, ws.CallCount("ListTrackingPlans"), "the cache should be populated once")
+}
+
+func TestResourceSource_concurrentReadsShareTrackingPlanCache(t *testing.T) {
+	ctx := context.Background()
+	ws := fake.NewWorkspace(unitTestWorkspace)
+	tp, err := ws.CreateTrackingPlan(ctx, segment.TrackingPlan{DisplayName: "Plan"})
+	require.NoError(t, err)
+	tpID := utils.PathToName(tp.Name)
+
+	const sources = 20
+	for i := 0; i < sources; i++ {
+		src := fmt.Sprintf("source-%d", i)
+		_, err := ws.CreateSource(ctx, src, "catalog/sources/javascript")
+		require.NoError(t, err)
+		require.NoError(t, ws.CreateTrackingPlanSourceConnection(ctx, tpID, src))
+	}
+	meta := unitTestMeta(ws)
+
+	resources := make([]*unitTestResource, sources)
+	var wg sync.WaitGroup
+	for i := range resources {
+		resources[i] = newUnitTestResourceWithMeta(t, "segment_source", meta)
+		wg.Add(1)
+		go func(r *unitTestResource, src string) {
+			defer wg.Done()
+			r.importState(src)
+		}(resources[i], fmt.Sprintf("source-%d", i))
+	}
+	wg.Wait()
+
+	for _, r := range resources {
+		assert.Equal(t, tpID, r.attr("tracking_plan"))
+	}
+	assert.Equal(t, 1, ws.CallCount("ListTrackingPlans"))
+}
+
+func TestResourceSource_deletedOutsideTerraform(t *testing.T) {
+	ctx := context.Background()
+	ws := fake.NewWorkspace(unitTestWorkspace)
+
+	r := newUnitTestResource(t, "segment_source", ws)
+	config := map[string]interface{}{
+		"source_name":  "my-source",
+		"catalog_name": "catalog/sources/javascript",
+	}
+	require.Empty(t, r.apply(config))
+	require.NoError(t, ws.DeleteSource(ctx, "my-source"))
+
+	require.Empty(t, r.refresh())
+	assert.Empty(t, r.id(), "the source should be removed from the state")
+	assert.NotNil(t, r.plan(config), "the source should be recreated")
+}
+
+func TestResourceSource_readFailure(t *testing.T) {
+	ws := fake.NewWorkspace(unitTestWorkspace)
+
+	r := newUnitTestResource(t, "segment_source", ws)
+	require.Empty(t, r.apply(map[string]interface{}{
+		"source_name":  "my-source",
+		"catalog_name": "catalog/sources/javascript",
+	}))
+	ws.InjectFault(fake.Fault{Method: "GetSource", Code: http.StatusForbidden, Times: 1})
+
+	assert.True(t, r.refresh().HasError(), "only not found errors should remove the source from the state")
+}
+
+func TestResourceSource_unknownCatalog(t *testing.T) {
+	ws := fake.NewWorkspace(unitTestWorkspace)
+
+	r := newUnitTestResource(t, "segment_source", ws)
+	d := r.apply(map[string]interface{}{
+		"source_name":  "my-source",
+		"catalog_name": "javascript",
+	})
+
+	require.Len(t, d, 1)
+	assert.Equal(t, "Unknown catalog", d[0].Summary)
+	assert.Contains(t, d[0].Detail, "javascript is not in the Segment catalog")
+	assert.Equal(t, cty.GetAttrPath("catalog_name"), d[0].AttributePath)
+}
+
+func TestResourceSource_invalidToken(t *testing.T) {
+	ws := fake.NewWorkspace(unitTestWorkspace)
+
+	r := newUnitTestResource(t, "segment_source", ws)
+	require.Empty(t, r.apply(map[string]interface{}{
+		"source_name":  "my-source",
+		"catalog_name": "catalog/sources/javascript",
+	}))
+	ws.InjectFault(fake.Fault{Method: "GetSource", Code: http.StatusUnauthorized, Times: 1})
+
+	d := r.refresh()
+	require.Len(t, d, 1)
+	assert.Equal(t, "Invalid Segment access token", d[0].Summary)
+	assert.Contains(t, d[0].Detail, "read source my-source")
+	assert.Nil(t, d[0].AttributePath, "the token is not an attribute of the resource")
+}
+
+func TestResourceSource_deletionProtection(t *testing.T) {
+	ctx := context.Background()
+	ws := fake.NewWorkspace(unitTestWorkspace)
+
+	r := newUnitTestResource(t, "segment_source", ws)
+	config := map[string]interface{}{
+		"source_name":         "my-source",
+		"catalog_name":        "catalog/sources/javascript",
+		"deletion_protection": true,
+	}
+	require.Empty(t, r.apply(config))
+	assert.Equal(t, "true", r.attr("deletion_protection"))
+
+	// Deletion is refused
+	d := r.destroy()
+	require.Len(t, d, 1)
+	assert.Equal(t, "Deletion protection enabled", d[0].Summary)
+	assert.Equal(t, cty.GetAttrPath("deletion_protection"), d[0].AttributePath)
+	assert.Zero(t, ws.CallCount("DeleteSource"))
+	_, err := ws.GetSource(ctx, "my-source")
+	assert.NoError(t, err)
+
+	// Replacement is refused, even when lifting the protection at the same time
+	err = r.planError(map[string]interface{}{
+		"source_name":         "my-source",
+		"catalog_name":        "catalog/sources/python",
+		"deletion_protection": false,
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "refusing to replace source my-source as its deletion_protection is true")
+		assert.Contains(t, err.Error(), "changing catalog_name")
+	}
+
+	// Lifting the protection doesn't call Segment, and allows deleting the source
+	calls := ws.CallCount("")
+	config["deletion_protection"] = false
+	require.Empty(t, r.apply(config))
+	assert.Equal(t, calls+1, ws.CallCount(""), "only the source should be read")
+	require.Empty(t, r.destroy())
+	_, err = ws.GetSource(ctx, "my-source")
+	assert.Error(t, err)
+}
+
+func TestResourceSource_importDefaultsDeletionProtection(t *testing.T) {
+	ws := fake.NewWorkspace(unitTestWorkspace)
+	_, err := ws.CreateSource(context.Background(), "my-source", "catalog/sources/javascript")
+	require.NoError(t, err)
+
+	r := newUnitTestResource(t, "segment_source", ws)
+	require.Empty(t, r.importState("my-source"))
+
+	assert.Equal(t, "false", r.attr("deletion_protection"))
+	assert.Nil(t, r.plan(map[string]interface{}{
+		"source_name":  "my-source",
+		"catalog_name": "catalog/sources/javascript",
+	}))
+}
+
+func TestResourceSource_createFailureReportsLingeringSource(t *testing.T) {
+	ctx := context.Background()
+	ws := fake.NewWorkspace(unitTestWorkspace)
+	ws.InjectFault(fake.Fault{Method: "DeleteSource", Code: http.StatusServiceUnavailable, Times: 1})
+
+	r := newUnitTestResource(t, "segment_source", ws)
+	d := r.apply(map[string]interface{}{
+		"source_name":   "my-source",
+		"catalog_name":  "catalog/sources/javascript",
+		"tracking_plan": "rs_does_not_exist",
+	})
+
+	require.Len(t, d, 2)
+	assert.Equal(t, "Lingering Segment resources", d[1].Summary)
+	assert.Contains(t, d[1].Detail, "Source my-source could not be cleaned up")
+	assert.Empty(t, r.id())
+	_, err := ws.GetSource(ctx, "my-source")
+	assert.NoError(t, err)
+}
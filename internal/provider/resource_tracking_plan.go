@@ -3,11 +3,11 @@ package provider
 import (
 	"context"
 	"encoding/json"
-	"log"
 	"regexp"
 	"sort"
 
 	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
@@ -16,17 +16,19 @@ import (
 )
 
 func resourceTrackingPlan() *schema.Resource {
-	return &schema.Resource{
+	return withDeletionProtection("tracking plan", &schema.Resource{
 		Description:   "A Segment tracking plan which outlines the events and properties to be collected across Segment Sources. More information can be found in the [Tracking Plans documentation](https://segment.com/docs/protocols/tracking-plan/create/).",
-		CreateContext: resourceTrackingPlanCreate,
-		ReadContext:   resourceTrackingPlanRead,
-		UpdateContext: resourceTrackingPlanUpdate,
-		DeleteContext: resourceTrackingPlanDelete,
+		CreateContext: operation("create segment_tracking_plan", resourceTrackingPlanCreate),
+		ReadContext:   operation("read segment_tracking_plan", resourceTrackingPlanRead),
+		UpdateContext: operation("update segment_tracking_plan", resourceTrackingPlanUpdate),
+		DeleteContext: operation("delete segment_tracking_plan", resourceTrackingPlanDelete),
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: importWithWorkspace(1),
 		},
+		Timeouts:      resourceTimeouts(),
 		SchemaVersion: 2,
 		Schema: map[string]*schema.Schema{
+			keyWorkspace: workspaceSchema("tracking plan"),
 			"name": {
 				Description: "The ID of the tracking plan. *(Set by Segment).*",
 				Type:        schema.TypeString,
@@ -67,7 +69,7 @@ func resourceTrackingPlan() *schema.Resource {
 		StateUpgraders: []schema.StateUpgrader{
 			TpV1V2Upgrader(),
 		},
-	}
+	})
 }
 
 func validateEventLibConfig(i interface{}, _ cty.Path) diag.Diagnostics {
@@ -78,7 +80,7 @@ func validateEventLibConfig(i interface{}, _ cty.Path) diag.Diagnostics {
 }
 
 func resourceTrackingPlanCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	meta := m.(ProviderMetadata)
+	meta := resourceMeta(m, d)
 	client := meta.Client
 
 	// Read tracking plan rules
@@ -106,9 +108,9 @@ func resourceTrackingPlanCreate(ctx context.Context, d *schema.ResourceData, m i
 		DisplayName: d.Get("display_name").(string),
 		Rules:       tpRules,
 	}
-	response, err := client.CreateTrackingPlan(tp)
+	response, err := client.CreateTrackingPlan(ctx, tp)
 	if err != nil {
-		return diag.FromErr(err)
+		return protocolsErrorDiag(err, "create tracking plan "+tp.DisplayName, cty.GetAttrPath("rules_json_file"))
 	}
 
 	// SetId shoud utilise the calculated name part in the schema
@@ -116,18 +118,31 @@ func resourceTrackingPlanCreate(ctx context.Context, d *schema.ResourceData, m i
 	trackingPlanID := re.FindString(response.Name)
 	d.SetId(trackingPlanID)
 
-	return resourceTrackingPlanRead(ctx, d, m)
+	var rollback utils.Rollback
+	rollback.Add("Tracking plan "+trackingPlanID, func(ctx context.Context) error {
+		if err := client.DeleteTrackingPlan(ctx, trackingPlanID); err != nil {
+			return err
+		}
+		meta.TrackingPlansConnections.forgetTrackingPlan(trackingPlanID)
+		return nil
+	})
+
+	return rollback.OnError(ctx, d, resourceTrackingPlanRead(ctx, d, m))
 }
 
-func resourceTrackingPlanRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	log.Println("[INFO] Reading tracking plans")
+func resourceTrackingPlanRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
-	meta := m.(ProviderMetadata)
+	meta := resourceMeta(m, d)
 	client := meta.Client
 
-	tp, err := client.GetTrackingPlan(d.Id())
+	tp, err := client.GetTrackingPlan(ctx, d.Id())
+	if utils.IsNotFound(err) && !d.IsNewResource() {
+		tflog.Warn(ctx, "Tracking plan not found, removing it from the state", map[string]interface{}{"id": d.Id()})
+		d.SetId("")
+		return nil
+	}
 	if err != nil {
-		return diag.FromErr(err)
+		return protocolsErrorDiag(err, "read tracking plan "+d.Id(), cty.GetAttrPath("name"))
 	}
 	if err := d.Set("name", tp.Name); err != nil {
 		return diag.FromErr(err)
@@ -144,10 +159,8 @@ func resourceTrackingPlanRead(_ context.Context, d *schema.ResourceData, m inter
 
 	// Filter out library events so that we can accurately set/compare the source json file
 	// Read event library rules
-	log.Println("[INFO] Extracting event libs")
 	eventLibs, err := readEventLibs(d.GetOk("import_from"))
 	if err != nil {
-		log.Println("[ERROR] Libs extraction failed")
 		return diag.FromErr(err)
 	}
 
@@ -157,10 +170,8 @@ func resourceTrackingPlanRead(_ context.Context, d *schema.ResourceData, m inter
 	// Remove library events
 	var sourceEvents []segment.Event
 	libsCount := len(eventLibsFlat.Events)
-	log.Printf("[INFO] Searching %d lib events", libsCount)
 	for _, evnt := range tp.Rules.Events {
 		found := utils.Search(libsCount, func(i int) bool {
-			log.Printf("[INFO] comparing index %d -  %s == %s", i, evnt.Name, eventLibsFlat.Events[i].Name)
 			isLibEvent := evnt.Name == eventLibsFlat.Events[i].Name
 
 			if isLibEvent {
@@ -169,15 +180,21 @@ func resourceTrackingPlanRead(_ context.Context, d *schema.ResourceData, m inter
 
 			return isLibEvent
 		})
-		log.Printf("[INFO] %s found at %d", evnt.Name, found)
+		tflog.SubsystemTrace(ctx, utils.LogTrackingPlanMerge, "Compared an event with the event libraries", map[string]interface{}{
+			"event":         evnt.Name,
+			"library_event": found >= 0,
+		})
 		if found < 0 {
 			sourceEvents = append(sourceEvents, evnt)
 		}
 	}
 
-	log.Printf("[INFO] Found %d source events", len(sourceEvents))
+	tflog.SubsystemDebug(ctx, utils.LogTrackingPlanMerge, "Separated the library events from the tracking plan rules", map[string]interface{}{
+		"id":             d.Id(),
+		"library_events": libsCount,
+		"rules_events":   len(sourceEvents),
+	})
 
-	log.Println("[INFO] Updating event libs state from remote")
 	var libsConfig string
 	if eventLibs != nil {
 		rawLibs, err := json.Marshal(eventLibs)
@@ -206,7 +223,7 @@ func resourceTrackingPlanRead(_ context.Context, d *schema.ResourceData, m inter
 
 func resourceTrackingPlanUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
-	meta := m.(ProviderMetadata)
+	meta := resourceMeta(m, d)
 	client := meta.Client
 
 	tpID := d.Id()
@@ -238,9 +255,9 @@ func resourceTrackingPlanUpdate(ctx context.Context, d *schema.ResourceData, m i
 			DisplayName: displayName,
 			Rules:       tpRules,
 		}
-		_, err = client.UpdateTrackingPlan(tpID, tp)
+		_, err = client.UpdateTrackingPlan(ctx, tpID, tp)
 		if err != nil {
-			return diag.FromErr(err)
+			return protocolsErrorDiag(err, "update tracking plan "+tpID, cty.GetAttrPath("rules_json_file"))
 		}
 
 		return resourceTrackingPlanRead(ctx, d, m)
@@ -249,21 +266,25 @@ func resourceTrackingPlanUpdate(ctx context.Context, d *schema.ResourceData, m i
 }
 
 func resourceTrackingPlanDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	meta := m.(ProviderMetadata)
+	meta := resourceMeta(m, d)
 	client := meta.Client
 
-	err := client.DeleteTrackingPlan(d.Id())
+	if diags := refuseProtectedDeletion(d, "delete tracking plan "+d.Id()); diags != nil {
+		return diags
+	}
+
+	err := client.DeleteTrackingPlan(ctx, d.Id())
 	if err != nil {
-		return diag.FromErr(err)
+		return protocolsErrorDiag(err, "delete tracking plan "+d.Id(), cty.GetAttrPath("name"))
 	}
+	meta.TrackingPlansConnections.forgetTrackingPlan(d.Id())
 
 	d.SetId("")
 
-	return resourceTrackingPlanRead(ctx, d, m)
+	return nil
 }
 
 func flattenEventLibs(eventLibs []segment.RuleSet) segment.RuleSet {
-	log.Println("[INFO] Flattening event libs")
 	var eventLibsFlat segment.RuleSet
 	for _, evtLib := range eventLibs {
 		eventLibsFlat.Events = append(eventLibsFlat.Events, evtLib.Events...)
@@ -276,9 +297,7 @@ func flattenEventLibs(eventLibs []segment.RuleSet) segment.RuleSet {
 }
 
 func readEventLibs(eventLibsIntfcs interface{}, ok bool) ([]segment.RuleSet, error) {
-	log.Println("[INFO] Reading event libs")
 	if !ok || eventLibsIntfcs == nil {
-		log.Println("[INFO] No libs defined")
 		return nil, nil
 	}
 
@@ -287,7 +306,6 @@ func readEventLibs(eventLibsIntfcs interface{}, ok bool) ([]segment.RuleSet, err
 		return nil, err
 	}
 
-	log.Println("[INFO] Successfully read event libs")
 	return decodedEventLibs, nil
 }
 
@@ -324,9 +342,7 @@ func mergeEvents(evtLibEvents []segment.Event, tpEvents []segment.Event) []segme
 // V1 -> V2
 
 func readEventLibsV1(eventLibsIntfcs interface{}, ok bool) ([]segment.RuleSet, error) {
-	log.Println("[INFO] Reading v1 event libs")
 	if !ok || eventLibsIntfcs == nil {
-		log.Println("[INFO] No libs defined")
 		return nil, nil
 	}
 
@@ -384,30 +400,28 @@ func tpResourceV1() *schema.Resource {
 func TpV1V2Upgrader() schema.StateUpgrader {
 	return schema.StateUpgrader{
 		Type: tpResourceV1().CoreConfigSchema().ImpliedType(),
-		Upgrade: func(_ context.Context, rawState map[string]interface{}, _ interface{}) (map[string]interface{}, error) {
-			log.Println("[INFO] Migrating Schema V1 -> V2")
+		Upgrade: func(ctx context.Context, rawState map[string]interface{}, _ interface{}) (map[string]interface{}, error) {
+			ctx = utils.WithLogging(ctx, map[string]interface{}{"operation": "upgrade segment_tracking_plan", "id": rawState["id"]})
 			value, ok := rawState["import_from"]
 
 			old, err := readEventLibsV1(value, ok)
 			if err != nil {
-				log.Println("[INFO] Migration V1 -> V2 failed")
 				return nil, err
 			}
 
 			if old == nil {
-				log.Println("[INFO] import_from not set, skipping")
+				tflog.Debug(ctx, "import_from not set, skipping the migration of the state from V1 to V2")
 				return rawState, nil
 			}
 
 			libsJSON, err := json.Marshal(old)
 			if err != nil {
-				log.Println("[INFO] Migration V1 -> V2 failed")
 				return nil, err
 			}
 
 			rawState["import_from"] = string(libsJSON)
 
-			log.Println("[INFO] Successfully migrated V1 -> V2")
+			tflog.Info(ctx, "Migrated the state from V1 to V2", map[string]interface{}{"event_libraries": len(old)})
 			return rawState, nil
 		},
 		Version: 1,
@@ -0,0 +1,105 @@
+package provider_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uswitch/terraform-provider-segment/internal/fake"
+)
+
+// testTrackingPlanRules are in the same shape as the rules returned by Segment, so that no diff is planned after reading them
+const testTrackingPlanRules = `{
+	"global": {"properties": {"context": {}, "properties": {}, "traits": {}}},
+	"events": [{
+		"name": "Order Completed",
+		"description": "An order was completed",
+		"rules": {"type": "object", "properties": {"context": {}, "properties": {}, "traits": {}}}
+	}],
+	"identify": {"properties": {"context": {}, "properties": {}, "traits": {}}},
+	"group": {"properties": {"context": {}, "properties": {}, "traits": {}}}
+}`
+
+func TestResourceTrackingPlan_lifecycle(t *testing.T) {
+	ctx := context.Background()
+	ws := fake.NewWorkspace(unitTestWorkspace)
+
+	r := newUnitTestResource(t, "segment_tracking_plan", ws)
+	config := map[string]interface{}{
+		"display_name":    "My Plan",
+		"rules_json_file": testTrackingPlanRules,
+	}
+
+	// Create
+	require.Empty(t, r.apply(config))
+	assert.Regexp(t, `^rs_\d+$`, r.id())
+	assert.Equal(t, "workspaces/unit-test/tracking-plans/"+r.id(), r.attr("name"))
+
+	// Update
+	config["display_name"] = "My Renamed Plan"
+	require.Empty(t, r.apply(config))
+	tp, err := ws.GetTrackingPlan(ctx, r.id())
+	require.NoError(t, err)
+	assert.Equal(t, "My Renamed Plan", tp.DisplayName)
+	require.Len(t, tp.Rules.Events, 1)
+	assert.Equal(t, "Order Completed", tp.Rules.Events[0].Name)
+
+	// Read
+	require.Empty(t, r.refresh())
+	assert.Nil(t, r.plan(config))
+
+	// Delete
+	id := r.id()
+	require.Empty(t, r.destroy())
+	_, err = ws.GetTrackingPlan(ctx, id)
+	assert.Error(t, err)
+}
+
+func TestResourceTrackingPlan_deletedOutsideTerraform(t *testing.T) {
+	ctx := context.Background()
+	ws := fake.NewWorkspace(unitTestWorkspace)
+
+	r := newUnitTestResource(t, "segment_tracking_plan", ws)
+	config := map[string]interface{}{
+		"display_name":    "My Plan",
+		"rules_json_file": testTrackingPlanRules,
+	}
+	require.Empty(t, r.apply(config))
+	require.NoError(t, ws.DeleteTrackingPlan(ctx, r.id()))
+
+	require.Empty(t, r.refresh())
+	assert.Empty(t, r.id(), "the tracking plan should be removed from the state")
+	assert.NotNil(t, r.plan(config), "the tracking plan should be recreated")
+}
+
+func TestResourceTrackingPlan_deletionProtection(t *testing.T) {
+	ctx := context.Background()
+	ws := fake.NewWorkspace(unitTestWorkspace)
+
+	r := newUnitTestResource(t, "segment_tracking_plan", ws)
+	config := map[string]interface{}{
+		"display_name":        "My Plan",
+		"rules_json_file":     testTrackingPlanRules,
+		"deletion_protection": true,
+	}
+	require.Empty(t, r.apply(config))
+
+	d := r.destroy()
+	require.Len(t, d, 1)
+	assert.Equal(t, "Deletion protection enabled", d[0].Summary)
+	assert.Contains(t, d[0].Detail, "Refusing to delete tracking plan "+r.id())
+	_, err := ws.GetTrackingPlan(ctx, r.id())
+	assert.NoError(t, err)
+
+	config["workspace"] = "other-workspace"
+	assert.Error(t, r.planError(config), "moving the tracking plan to another workspace should be refused")
+	delete(config, "workspace")
+
+	config["deletion_protection"] = false
+	require.Empty(t, r.apply(config))
+	id := r.id()
+	require.Empty(t, r.destroy())
+	_, err = ws.GetTrackingPlan(ctx, id)
+	assert.Error(t, err)
+}
@@ -0,0 +1,124 @@
+package provider_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uswitch/terraform-provider-segment/internal/fake"
+)
+
+func TestRollback_readFailureAfterCreateCleansUp(t *testing.T) {
+	ctx := context.Background()
+	withSource := func(t *testing.T, ws *fake.Workspace) {
+		_, err := ws.CreateSource(ctx, "my-source", "catalog/sources/javascript")
+		require.NoError(t, err)
+	}
+
+	for _, tc := range []struct {
+		resource string
+		setup    func(t *testing.T, ws *fake.Workspace)
+		config   map[string]interface{}
+		read     string
+		delete   string
+		exists   func(t *testing.T, ws *fake.Workspace) bool
+	}{
+		{
+			resource: "segment_source",
+			setup:    func(*testing.T, *fake.Workspace) {},
+			config: map[string]interface{}{
+				"source_name":  "my-source",
+				"catalog_name": "catalog/sources/javascript",
+			},
+			read:   "GetSource",
+			delete: "DeleteSource",
+			exists: func(t *testing.T, ws *fake.Workspace) bool {
+				_, err := ws.GetSource(ctx, "my-source")
+				return err == nil
+			},
+		},
+		{
+			resource: "segment_destination",
+			setup:    withSource,
+			config: map[string]interface{}{
+				"source":  "my-source",
+				"name":    "google-analytics",
+				"enabled": true,
+				"config":  map[string]interface{}{},
+			},
+			read:   "GetDestination",
+			delete: "DeleteDestination",
+			exists: func(t *testing.T, ws *fake.Workspace) bool {
+				_, err := ws.GetDestination(ctx, "my-source", "google-analytics")
+				return err == nil
+			},
+		},
+		{
+			resource: "segment_destination_filter",
+			setup: func(t *testing.T, ws *fake.Workspace) {
+				withSource(t, ws)
+				_, err := ws.CreateDestination(ctx, "my-source", "rtb-house", "UNSPECIFIED", true, nil)
+				require.NoError(t, err)
+			},
+			config: map[string]interface{}{
+				"destination": "my-source/rtb-house",
+				"title":       "Foo",
+				"condition":   "context.castPermissions.marketing = false",
+				"actions": []interface{}{map[string]interface{}{
+					"drop": []interface{}{map[string]interface{}{}},
+				}},
+			},
+			read:   "GetDestinationFilter",
+			delete: "DeleteDestinationFilter",
+			exists: func(t *testing.T, ws *fake.Workspace) bool {
+				filters, err := ws.ListDestinationFilters(ctx, "my-source", "rtb-house")
+				require.NoError(t, err)
+				return len(filters) > 0
+			},
+		},
+		{
+			resource: "segment_tracking_plan",
+			setup:    func(*testing.T, *fake.Workspace) {},
+			config: map[string]interface{}{
+				"display_name":    "My Plan",
+				"rules_json_file": testTrackingPlanRules,
+			},
+			read:   "GetTrackingPlan",
+			delete: "DeleteTrackingPlan",
+			exists: func(t *testing.T, ws *fake.Workspace) bool {
+				plans, err := ws.ListTrackingPlans(ctx)
+				require.NoError(t, err)
+				return len(plans.TrackingPlans) > 0
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.resource, func(t *testing.T) {
+			ws := fake.NewWorkspace(unitTestWorkspace)
+			tc.setup(t, ws)
+			r := newUnitTestResource(t, tc.resource, ws)
+
+			// The failed read deletes the resource
+			ws.InjectFault(fake.Fault{Method: tc.read, Code: http.StatusBadGateway, Times: 1})
+			d := r.apply(tc.config)
+
+			require.Len(t, d, 1)
+			assert.Equal(t, "Segment Config API error", d[0].Summary)
+			assert.Empty(t, r.id())
+			assert.False(t, tc.exists(t, ws), "the resource should have been deleted after the failed read")
+
+			// A failed deletion is reported
+			ws.InjectFault(fake.Fault{Method: tc.read, Code: http.StatusBadGateway, Times: 1})
+			ws.InjectFault(fake.Fault{Method: tc.delete, Code: http.StatusBadGateway, Times: 1})
+			d = r.apply(tc.config)
+
+			require.Len(t, d, 2)
+			assert.Equal(t, "Segment Config API error", d[0].Summary)
+			assert.Equal(t, "Lingering Segment resources", d[1].Summary)
+			assert.Empty(t, r.id())
+			assert.True(t, tc.exists(t, ws))
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/uswitch/segment-config-go/segment"
+	"github.com/uswitch/terraform-provider-segment/internal/configapi"
+)
+
+// SegmentAPI is the set of Config API operations used by the provider resources.
+// It is implemented by configapi.Client, and by fake.Workspace for unit tests.
+// readOnlyClient implements every operation explicitly, refusing the ones mutating the workspace.
+type SegmentAPI interface {
+	// Workspaces
+	GetWorkspace(ctx context.Context) (segment.Workspace, error)
+
+	// Sources
+	ListSources(ctx context.Context) ([]configapi.Source, error)
+	GetSource(ctx context.Context, srcName string) (configapi.Source, error)
+	CreateSource(ctx context.Context, srcName string, catName string) (configapi.Source, error)
+	DeleteSource(ctx context.Context, srcName string) error
+	GetSourceConfig(ctx context.Context, srcName string) (segment.SourceConfig, error)
+	UpdateSourceConfig(ctx context.Context, srcName string, config segment.SourceConfig) (segment.SourceConfig, error)
+
+	// Destinations
+	GetDestination(ctx context.Context, srcName string, destName string) (segment.Destination, error)
+	CreateDestination(ctx context.Context, srcName string, destName string, connMode string, enabled bool, configs []segment.DestinationConfig) (segment.Destination, error)
+	UpdateDestination(ctx context.Context, srcName string, destName string, enabled bool, configs []segment.DestinationConfig) (segment.Destination, error)
+	DeleteDestination(ctx context.Context, srcName string, destName string) error
+
+	// Destination filters
+	GetDestinationFilter(ctx context.Context, srcName string, destName string, filterID string) (*segment.DestinationFilter, error)
+	CreateDestinationFilter(ctx context.Context, srcName string, destName string, filter segment.DestinationFilter) (*segment.DestinationFilter, error)
+	UpdateDestinationFilter(ctx context.Context, srcName string, destName string, filter segment.DestinationFilter) (*segment.DestinationFilter, error)
+	DeleteDestinationFilter(ctx context.Context, srcName string, destName string, filterID string) error
+
+	// Tracking plans
+	ListTrackingPlans(ctx context.Context) (segment.TrackingPlans, error)
+	GetTrackingPlan(ctx context.Context, trackingPlanID string) (segment.TrackingPlan, error)
+	CreateTrackingPlan(ctx context.Context, data segment.TrackingPlan) (segment.TrackingPlan, error)
+	UpdateTrackingPlan(ctx context.Context, trackingPlanID string, data segment.TrackingPlan) (segment.TrackingPlan, error)
+	DeleteTrackingPlan(ctx context.Context, trackingPlanID string) error
+
+	// Tracking plan source connections
+	ListTrackingPlanSources(ctx context.Context, planID string) ([]segment.TrackingPlanSourceConnection, error)
+	CreateTrackingPlanSourceConnection(ctx context.Context, planID string, srcName string) error
+	DeleteTrackingPlanSourceConnection(ctx context.Context, planID string, srcName string) error
+
+	// Catalog
+	GetCatalogSource(ctx context.Context, srcName string) (configapi.CatalogEntry, error)
+	GetCatalogDestination(ctx context.Context, destName string) (configapi.CatalogEntry, error)
+}
+
+var _ SegmentAPI = (*configapi.Client)(nil)
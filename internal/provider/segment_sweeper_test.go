@@ -1,6 +1,8 @@
 package provider_test
 
 import (
+	"context"
+	"errors"
 	"log"
 	"os"
 	"strings"
@@ -8,11 +10,24 @@ import (
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
-	"github.com/uswitch/segment-config-go/segment"
+	"github.com/uswitch/terraform-provider-segment/internal/configapi"
+	"github.com/uswitch/terraform-provider-segment/internal/mockapi"
 	"github.com/uswitch/terraform-provider-segment/internal/utils"
 )
 
+// mockServer is the local Config API server the acceptance tests run against when TF_ACC_MOCK=1, nil otherwise.
+// Tests can use it to inject faults, e.g. mockServer.InjectFault(fake.Fault{Method: "CreateSource", Code: 429, Times: 1})
+var mockServer *mockapi.Server
+
 func TestMain(m *testing.M) {
+	if os.Getenv("TF_ACC_MOCK") == "1" {
+		mockServer = mockapi.NewServer("test-acc-mock", "test-acc-mock-token")
+		os.Setenv("SEGMENT_ACCESS_TOKEN", mockServer.Token)
+		os.Setenv("SEGMENT_WORKSPACE", mockServer.Workspace.Slug())
+		os.Setenv("SEGMENT_ENDPOINT", mockServer.URL)
+		log.Printf("[INFO] Running acceptance tests against the mock Config API at %s", mockServer.URL)
+	}
+
 	resource.TestMain(m)
 }
 
@@ -23,30 +38,24 @@ func TestMain(m *testing.M) {
 //		resource.AddTestSweepers("unique-name", sourceSweeper("unique-name"))
 //	}
 func sourceSweeper(name string) *resource.Sweeper {
-	var token, workspace string
-	ok := false
-	if token, ok = os.LookupEnv("SEGMENT_ACCESS_TOKEN"); !ok {
-		panic("SEGMENT_ACCESS_TOKEN must be set for acceptance tests")
-	}
-	if workspace, ok = os.LookupEnv("SEGMENT_WORKSPACE"); !ok {
-		panic("SEGMENT_WORKSPACE must be set for acceptance tests")
-	}
-
-	client := segment.NewClient(token, workspace)
-
 	sweep := func(_ string) error {
-		sources, err := client.ListSources()
+		client, err := sweeperClient()
+		if err != nil {
+			return err
+		}
 
-		log.Printf("[INFO] Sweeping through %d sources", len(sources.Sources))
+		sources, err := client.ListSources(context.Background())
+
+		log.Printf("[INFO] Sweeping through %d sources", len(sources))
 
 		var errs error
 		deleted := 0
-		for _, source := range sources.Sources {
+		for _, source := range sources {
 			src := utils.PathToName(source.Name)
 			log.Printf("[INFO] Checking source %s", src)
 			if strings.HasPrefix(src, testPrefix) {
 				log.Printf("[INFO] Deleting source %s", source.Name)
-				if multierror.Append(errs, client.DeleteSource(source.Name)) != nil {
+				if multierror.Append(errs, client.DeleteSource(context.Background(), src)) != nil {
 					deleted += 1
 				}
 			}
@@ -62,3 +71,23 @@ func sourceSweeper(name string) *resource.Sweeper {
 		F:    sweep,
 	}
 }
+
+// sweeperClient creates a Config API client from the acceptance tests environment.
+// This is done lazily so that registering sweepers doesn't require the environment to be set.
+func sweeperClient() (*configapi.Client, error) {
+	token, ok := os.LookupEnv("SEGMENT_ACCESS_TOKEN")
+	if !ok {
+		return nil, errors.New("SEGMENT_ACCESS_TOKEN must be set for acceptance tests")
+	}
+	workspace, ok := os.LookupEnv("SEGMENT_WORKSPACE")
+	if !ok {
+		return nil, errors.New("SEGMENT_WORKSPACE must be set for acceptance tests")
+	}
+
+	opts := []configapi.Option{}
+	if endpoint, ok := os.LookupEnv("SEGMENT_ENDPOINT"); ok {
+		opts = append(opts, configapi.WithBaseURL(endpoint))
+	}
+
+	return configapi.NewClient(token, workspace, opts...), nil
+}
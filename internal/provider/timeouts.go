@@ -0,0 +1,28 @@
+package provider
+
+import (
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// defaultTimeout bounds every operation of a resource, retries included, when its timeouts block doesn't
+const defaultTimeout = 10 * time.Minute
+
+// resourceTimeouts declares the `timeouts` block of a resource. The SDK cancels the context of an operation once its
+// timeout elapses, which stops the retries and aborts the in-flight request to the Config API.
+func resourceTimeouts() *schema.ResourceTimeout {
+	return &schema.ResourceTimeout{
+		Create: schema.DefaultTimeout(defaultTimeout),
+		Read:   schema.DefaultTimeout(defaultTimeout),
+		Update: schema.DefaultTimeout(defaultTimeout),
+		Delete: schema.DefaultTimeout(defaultTimeout),
+	}
+}
+
+// dataSourceTimeouts declares the `timeouts` block of a data source, which only reads
+func dataSourceTimeouts() *schema.ResourceTimeout {
+	return &schema.ResourceTimeout{
+		Read: schema.DefaultTimeout(defaultTimeout),
+	}
+}
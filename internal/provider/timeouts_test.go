@@ -0,0 +1,70 @@
+package provider_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uswitch/terraform-provider-segment/internal/fake"
+	"github.com/uswitch/terraform-provider-segment/internal/mockapi"
+	"github.com/uswitch/terraform-provider-segment/internal/provider"
+)
+
+func TestTimeouts_declaredByResources(t *testing.T) {
+	for _, name := range []string{"segment_source", "segment_destination", "segment_destination_filter", "segment_tracking_plan"} {
+		r := provider.New().ResourcesMap[name]
+		if assert.NotNil(t, r.Timeouts, name) {
+			for _, timeout := range []*time.Duration{r.Timeouts.Create, r.Timeouts.Read, r.Timeouts.Update, r.Timeouts.Delete} {
+				assert.Equal(t, 10*time.Minute, *timeout, name)
+			}
+		}
+	}
+}
+
+func TestTimeouts_stopRetries(t *testing.T) {
+	server := mockapi.NewServer(unitTestWorkspace, "token")
+	defer server.Close()
+	meta := configureProvider(t, server, map[string]interface{}{
+		"max_retries":                 100,
+		"retry_initial_delay":         "20ms",
+		"retry_max_delay":             "20ms",
+		"skip_credentials_validation": true,
+	})
+	server.InjectFault(fake.Fault{Method: "CreateSource", Code: http.StatusTooManyRequests, Times: 1000})
+
+	r := newUnitTestResourceWithMeta(t, "segment_source", meta)
+	start := time.Now()
+	d := r.apply(map[string]interface{}{
+		"source_name":  "my-source",
+		"catalog_name": "catalog/sources/javascript",
+		"timeouts":     []interface{}{map[string]interface{}{"create": "200ms"}},
+	})
+
+	require.Len(t, d, 1)
+	assert.Equal(t, "Timeout", d[0].Summary)
+	assert.Contains(t, d[0].Detail, "create source my-source")
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Less(t, server.Workspace.CallCount("CreateSource"), 100, "retries should stop at the timeout")
+	assert.Empty(t, r.id())
+}
+
+func TestTimeouts_abortSlowRequests(t *testing.T) {
+	ws := fake.NewWorkspace(unitTestWorkspace)
+	r := newUnitTestResource(t, "segment_source", ws)
+	require.Empty(t, r.apply(map[string]interface{}{
+		"source_name":  "my-source",
+		"catalog_name": "catalog/sources/javascript",
+		"timeouts":     []interface{}{map[string]interface{}{"delete": "50ms"}},
+	}))
+
+	ws.SetLatency(time.Minute)
+	start := time.Now()
+	d := r.destroy()
+
+	require.Len(t, d, 1)
+	assert.Equal(t, "Timeout", d[0].Summary)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, "my-source", r.id(), "the source should stay in the state")
+}
@@ -0,0 +1,115 @@
+package provider_test
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uswitch/terraform-provider-segment/internal/fake"
+	"github.com/uswitch/terraform-provider-segment/internal/mockapi"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var (
+	spans          = tracetest.NewInMemoryExporter()
+	setupSpansOnce sync.Once
+)
+
+// recordSpans records the spans ended by the test. The global tracer provider can only be set once for the tracers
+// created beforehand, so it is shared by the tests.
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	setupSpansOnce.Do(func() {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(spans)))
+	})
+	spans.Reset()
+	t.Cleanup(spans.Reset)
+
+	return spans
+}
+
+// spansNamed returns the recorded spans with a name
+func spansNamed(exporter *tracetest.InMemoryExporter, name string) []tracetest.SpanStub {
+	var matching []tracetest.SpanStub
+	for _, span := range exporter.GetSpans() {
+		if span.Name == name {
+			matching = append(matching, span)
+		}
+	}
+
+	return matching
+}
+
+func spanAttributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attributes := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes {
+		attributes[kv.Key] = kv.Value
+	}
+
+	return attributes
+}
+
+func TestTracing_operationSpans(t *testing.T) {
+	server := mockapi.NewServer(unitTestWorkspace, "token")
+	defer server.Close()
+	meta := configureProvider(t, server, map[string]interface{}{
+		"max_retries":                 2,
+		"retry_initial_delay":         "1ms",
+		"skip_credentials_validation": true,
+	})
+	server.InjectFault(fake.Fault{Method: "CreateSource", Code: http.StatusTooManyRequests, Times: 1})
+	exporter := recordSpans(t)
+
+	r := newUnitTestResourceWithMeta(t, "segment_source", meta)
+	require.Empty(t, r.apply(map[string]interface{}{
+		"source_name":  "my-source",
+		"catalog_name": "catalog/sources/javascript",
+	}))
+
+	operations := spansNamed(exporter, "create segment_source")
+	require.Len(t, operations, 1)
+	create := operations[0]
+	assert.False(t, create.Parent.IsValid(), "operations should be root spans")
+	assert.Equal(t, "my-source", spanAttributes(create)["segment.id"].AsString())
+	assert.Equal(t, unitTestWorkspace, spanAttributes(create)["segment.workspace"].AsString())
+	assert.Equal(t, codes.Unset, create.Status.Code)
+
+	requests := spansNamed(exporter, "Config API POST")
+	require.Len(t, requests, 1)
+	assert.Equal(t, create.SpanContext.SpanID(), requests[0].Parent.SpanID())
+	assert.Equal(t, create.SpanContext.TraceID(), requests[0].SpanContext.TraceID())
+
+	attempts := spansNamed(exporter, "Config API attempt")
+	var retried []tracetest.SpanStub
+	for _, attempt := range attempts {
+		if attempt.Parent.SpanID() == requests[0].SpanContext.SpanID() {
+			retried = append(retried, attempt)
+		}
+	}
+	require.Len(t, retried, 2, "the failed attempt and its retry should be traced")
+	assert.Equal(t, int64(http.StatusTooManyRequests), spanAttributes(retried[0])["http.status_code"].AsInt64())
+	assert.Equal(t, codes.Error, retried[0].Status.Code)
+	assert.Equal(t, int64(http.StatusOK), spanAttributes(retried[1])["http.status_code"].AsInt64())
+}
+
+func TestTracing_failedOperation(t *testing.T) {
+	ws := fake.NewWorkspace(unitTestWorkspace)
+	ws.InjectFault(fake.Fault{Method: "CreateSource", Code: http.StatusForbidden, Times: 1})
+	exporter := recordSpans(t)
+
+	r := newUnitTestResource(t, "segment_source", ws)
+	require.True(t, r.apply(map[string]interface{}{
+		"source_name":  "my-source",
+		"catalog_name": "catalog/sources/javascript",
+	}).HasError())
+
+	operations := spansNamed(exporter, "create segment_source")
+	require.Len(t, operations, 1)
+	assert.Equal(t, codes.Error, operations[0].Status.Code)
+	assert.NotEmpty(t, operations[0].Status.Description)
+}
@@ -0,0 +1,218 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/uswitch/segment-config-go/segment"
+	"github.com/uswitch/terraform-provider-segment/internal/utils"
+)
+
+// TrackingPlansConnectionsCache maps sources to the ID of the tracking plan they are connected to.
+// Finding the tracking plan of a source requires listing the connections of every tracking plan of the workspace, so the
+// cache is populated once per run, on the first lookup, and then kept up to date as connections are created and deleted.
+// It is safe for concurrent use.
+type TrackingPlansConnectionsCache struct {
+	mu          sync.Mutex
+	populated   bool
+	connections map[string]string
+	// populating is closed when the running population ends, and nil when none is running
+	populating chan struct{}
+	// changes are the connections created and deleted while populating, replayed on the populated connections
+	changes     []func(connections map[string]string)
+	concurrency int
+}
+
+// NewTrackingPlansConnectionsCache creates an empty cache, populated by listing the connections of up to concurrency
+// tracking plans at once
+func NewTrackingPlansConnectionsCache(concurrency int) *TrackingPlansConnectionsCache {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	return &TrackingPlansConnectionsCache{connections: map[string]string{}, concurrency: concurrency}
+}
+
+// find returns the ID of the tracking plan connected to the source, or "" if the source is not connected.
+// The cache is populated on the first call; concurrent calls wait for it to be populated, and populate it again if it
+// failed.
+func (cache *TrackingPlansConnectionsCache) find(ctx context.Context, client SegmentAPI, source string) (string, error) {
+	for {
+		cache.mu.Lock()
+		if cache.populated {
+			tp := cache.connections[utils.PathToName(source)]
+			cache.mu.Unlock()
+
+			tflog.SubsystemTrace(ctx, utils.LogConnectionCache, "Looked up the tracking plan of a source", map[string]interface{}{
+				"source":        utils.PathToName(source),
+				"tracking_plan": tp,
+				"hit":           tp != "",
+			})
+			return tp, nil
+		}
+
+		if populating := cache.populating; populating != nil {
+			cache.mu.Unlock()
+			select {
+			case <-populating:
+				continue
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+		}
+
+		populating := make(chan struct{})
+		cache.populating = populating
+		cache.mu.Unlock()
+
+		connections, err := cache.populate(ctx, client)
+
+		cache.mu.Lock()
+		if err == nil {
+			for _, change := range cache.changes {
+				change(connections)
+			}
+			cache.connections = connections
+			cache.populated = true
+		}
+		cache.changes = nil
+		cache.populating = nil
+		close(populating)
+		cache.mu.Unlock()
+
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// connect records a new connection between a source and a tracking plan
+func (cache *TrackingPlansConnectionsCache) connect(source string, trackingPlanID string) {
+	cache.update(func(connections map[string]string) {
+		connections[utils.PathToName(source)] = trackingPlanID
+	})
+}
+
+// disconnect forgets the connection of a source
+func (cache *TrackingPlansConnectionsCache) disconnect(source string) {
+	cache.update(func(connections map[string]string) {
+		delete(connections, utils.PathToName(source))
+	})
+}
+
+// forgetTrackingPlan forgets the connections of a deleted tracking plan
+func (cache *TrackingPlansConnectionsCache) forgetTrackingPlan(trackingPlanID string) {
+	cache.update(func(connections map[string]string) {
+		for source, tp := range connections {
+			if tp == trackingPlanID {
+				delete(connections, source)
+			}
+		}
+	})
+}
+
+// update applies a change to the cached connections, and to the connections being populated if any, so it isn't lost
+// when they replace the cached ones
+func (cache *TrackingPlansConnectionsCache) update(change func(connections map[string]string)) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	change(cache.connections)
+	if cache.populating != nil {
+		cache.changes = append(cache.changes, change)
+	}
+}
+
+// populate lists the connections of every tracking plan, using a bounded pool of workers. It stops at the first failure
+// and returns the errors of all the failed tracking plans. It doesn't hold the lock, so the connections are only
+// cached by the caller once they are all listed.
+func (cache *TrackingPlansConnectionsCache) populate(ctx context.Context, client SegmentAPI) (map[string]string, error) {
+	tps, err := client.ListTrackingPlans(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	tpIDs := make(chan string)
+	results := make(chan trackingPlanConnections)
+	var wg sync.WaitGroup
+	for i := 0; i < cache.concurrency && i < len(tps.TrackingPlans); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for tpID := range tpIDs {
+				srcs, err := client.ListTrackingPlanSources(ctx, tpID)
+				results <- trackingPlanConnections{tpID, srcs, err}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(tpIDs)
+		for _, tp := range tps.TrackingPlans {
+			select {
+			case tpIDs <- utils.PathToName(tp.Name):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	connections := map[string]string{}
+	var errs error
+	for result := range results {
+		switch {
+		case result.err == nil:
+			addConnections(ctx, connections, result.connections)
+		case errors.Is(result.err, context.Canceled) && errs != nil:
+			// Cancelled because of a previous failure
+		default:
+			errs = multierror.Append(errs, fmt.Errorf("failed to list the sources of tracking plan %s: %w", result.trackingPlanID, result.err))
+			cancel()
+		}
+	}
+	if errs != nil {
+		return nil, errs
+	}
+
+	tflog.SubsystemInfo(ctx, utils.LogConnectionCache, "Populated the tracking plans connections cache", map[string]interface{}{
+		"tracking_plans": len(tps.TrackingPlans),
+		"entries":        len(connections),
+	})
+
+	return connections, nil
+}
+
+func addConnections(ctx context.Context, dst map[string]string, connections []segment.TrackingPlanSourceConnection) {
+	if len(connections) < 1 {
+		return
+	}
+
+	for _, currSrc := range connections {
+		source := utils.PathToName(currSrc.Source)
+		dst[source] = currSrc.TrackingPlanId
+	}
+
+	tflog.SubsystemDebug(ctx, utils.LogConnectionCache, "Cached the connections of a tracking plan", map[string]interface{}{
+		"tracking_plan": connections[0].TrackingPlanId,
+		"connections":   len(connections),
+		"entries":       len(dst),
+	})
+}
+
+type trackingPlanConnections struct {
+	trackingPlanID string
+	connections    []segment.TrackingPlanSourceConnection
+	err            error
+}
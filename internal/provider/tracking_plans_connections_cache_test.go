@@ -0,0 +1,126 @@
+package provider_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uswitch/segment-config-go/segment"
+	"github.com/uswitch/terraform-provider-segment/internal/fake"
+	"github.com/uswitch/terraform-provider-segment/internal/provider"
+	"github.com/uswitch/terraform-provider-segment/internal/utils"
+)
+
+// newWorkspaceWithTrackingPlans creates a workspace with the given number of tracking plans, each connected to one source.
+// It returns the name of the source connected to the last tracking plan, and the ID of that plan.
+func newWorkspaceWithTrackingPlans(t testing.TB, plans int) (ws *fake.Workspace, src string, tpID string) {
+	ctx := context.Background()
+	ws = fake.NewWorkspace(unitTestWorkspace)
+
+	for i := 0; i < plans; i++ {
+		tp, err := ws.CreateTrackingPlan(ctx, segment.TrackingPlan{DisplayName: fmt.Sprintf("Plan %d", i)})
+		require.NoError(t, err)
+		tpID = utils.PathToName(tp.Name)
+		src = fmt.Sprintf("source-%d", i)
+		_, err = ws.CreateSource(ctx, src, "catalog/sources/javascript")
+		require.NoError(t, err)
+		require.NoError(t, ws.CreateTrackingPlanSourceConnection(ctx, tpID, src))
+	}
+
+	return ws, src, tpID
+}
+
+func TestTrackingPlansConnectionsCache_failsFast(t *testing.T) {
+	ws, src, tpID := newWorkspaceWithTrackingPlans(t, 20)
+	ws.InjectFault(fake.Fault{Method: "ListTrackingPlanSources", Code: http.StatusBadGateway, Times: 2})
+	meta := unitTestMeta(ws)
+
+	r := newUnitTestResourceWithMeta(t, "segment_source", meta)
+	d := r.importState(src)
+
+	require.True(t, d.HasError())
+	assert.Contains(t, d[0].Detail, "failed to list the sources of tracking plan")
+	assert.Less(t, ws.CallCount("ListTrackingPlanSources"), 20, "the remaining tracking plans should be skipped")
+
+	// The cache is populated by the next lookup
+	r = newUnitTestResourceWithMeta(t, "segment_source", meta)
+	require.Empty(t, r.importState(src))
+	assert.Equal(t, tpID, r.attr("tracking_plan"))
+}
+
+func TestTrackingPlansConnectionsCache_discardsFailedPopulations(t *testing.T) {
+	ctx := context.Background()
+	ws, _, _ := newWorkspaceWithTrackingPlans(t, 2)
+	tps, err := ws.ListTrackingPlans(ctx)
+	require.NoError(t, err)
+	firstTpID := utils.PathToName(tps.TrackingPlans[0].Name)
+	firstSources, err := ws.ListTrackingPlanSources(ctx, firstTpID)
+	require.NoError(t, err)
+	firstSrc := utils.PathToName(firstSources[0].Source)
+
+	// The connections of the first tracking plan are listed before the second one fails
+	meta := unitTestMeta(ws)
+	meta.TrackingPlansConnections = provider.NewTrackingPlansConnectionsCache(1)
+	ws.InjectFault(fake.Fault{Method: "ListTrackingPlanSources", Code: http.StatusBadGateway, Times: 1, After: 1})
+	r := newUnitTestResourceWithMeta(t, "segment_source", meta)
+	require.True(t, r.importState(firstSrc).HasError())
+
+	require.NoError(t, ws.DeleteTrackingPlanSourceConnection(ctx, firstTpID, firstSrc))
+	r = newUnitTestResourceWithMeta(t, "segment_source", meta)
+	require.Empty(t, r.importState(firstSrc))
+	assert.Empty(t, r.attr("tracking_plan"), "the connections listed by the failed population should not be cached")
+}
+
+func TestTrackingPlansConnectionsCache_doesNotBlockConnections(t *testing.T) {
+	ws, src, tpID := newWorkspaceWithTrackingPlans(t, 20)
+	meta := unitTestMeta(ws)
+	meta.TrackingPlansConnections = provider.NewTrackingPlansConnectionsCache(1)
+	ws.SetLatency(20 * time.Millisecond)
+
+	imported := make(chan diag.Diagnostics, 1)
+	importer := newUnitTestResourceWithMeta(t, "segment_source", meta)
+	go func() { imported <- importer.importState(src) }()
+	require.Eventually(t, func() bool { return ws.CallCount("ListTrackingPlanSources") > 0 }, time.Second, time.Millisecond)
+
+	r := newUnitTestResourceWithMeta(t, "segment_source", meta)
+	require.Empty(t, r.apply(map[string]interface{}{
+		"source_name":   "new-source",
+		"catalog_name":  "catalog/sources/javascript",
+		"tracking_plan": tpID,
+	}))
+	select {
+	case <-imported:
+		t.Fatal("the source should be connected while the cache is being populated")
+	default:
+	}
+
+	require.Empty(t, <-imported)
+	assert.Equal(t, tpID, importer.attr("tracking_plan"))
+}
+
+func BenchmarkTrackingPlansConnectionsCache(b *testing.B) {
+	ws, src, _ := newWorkspaceWithTrackingPlans(b, 150)
+	ws.SetLatency(time.Millisecond)
+
+	for _, concurrency := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("concurrency=%d", concurrency), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				meta := unitTestMeta(ws)
+				meta.TrackingPlansConnections = provider.NewTrackingPlansConnectionsCache(concurrency)
+				r, ok := provider.New().ResourcesMap["segment_source"]
+				require.True(b, ok)
+
+				d := r.Data(nil)
+				d.SetId(src)
+				if diags := r.ReadContext(context.Background(), d, meta); diags.HasError() {
+					b.Fatal(diags)
+				}
+			}
+		})
+	}
+}
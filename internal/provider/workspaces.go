@@ -0,0 +1,159 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const keyWorkspace = "workspace"
+
+// workspaceSchema is the schema of the attribute overriding the workspace of the provider for a resource. It is only
+// stored when set in the config, so resources without it follow the workspace of the provider.
+func workspaceSchema(resource string) *schema.Schema {
+	return &schema.Schema{
+		Description: fmt.Sprintf("The slug of the workspace the %s belongs to. Defaults to the `workspace` of the provider.", resource),
+		Type:        schema.TypeString,
+		Optional:    true,
+		ForceNew:    true,
+	}
+}
+
+// dataSourceWorkspaceSchema is the schema of the attribute overriding the workspace of the provider for a data source
+func dataSourceWorkspaceSchema(description string) *schema.Schema {
+	return &schema.Schema{
+		Description: description,
+		Type:        schema.TypeString,
+		Optional:    true,
+	}
+}
+
+// WorkspaceClients creates the clients of the workspaces resources are managed in, and caches them so all the resources
+// of a workspace share a client and a tracking plan connections cache
+type WorkspaceClients struct {
+	mu               sync.Mutex
+	newClient        func(workspace string) SegmentAPI
+	cacheConcurrency int
+	// checkCredentials enables the validation of the credentials of each workspace, the first time it is used
+	checkCredentials bool
+	workspaces       map[string]*workspaceClient
+}
+
+type workspaceClient struct {
+	client      SegmentAPI
+	connections *TrackingPlansConnectionsCache
+	validated   bool
+}
+
+// NewWorkspaceClients creates an empty set of workspace clients, using newClient to create the client of a workspace
+// the first time it is needed
+func NewWorkspaceClients(newClient func(workspace string) SegmentAPI, cacheConcurrency int) *WorkspaceClients {
+	return &WorkspaceClients{
+		newClient:        newClient,
+		cacheConcurrency: cacheConcurrency,
+		workspaces:       map[string]*workspaceClient{},
+	}
+}
+
+func (w *WorkspaceClients) get(workspace string) *workspaceClient {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	c, ok := w.workspaces[workspace]
+	if !ok {
+		c = &workspaceClient{
+			client:      w.newClient(workspace),
+			connections: NewTrackingPlansConnectionsCache(w.cacheConcurrency),
+		}
+		w.workspaces[workspace] = c
+	}
+
+	return c
+}
+
+// validate checks the access token can access a workspace the first time it is used, when credentials validation is
+// enabled. A failed validation is attempted again the next time the workspace is used.
+func (w *WorkspaceClients) validate(ctx context.Context, workspace string) diag.Diagnostics {
+	if w == nil || !w.checkCredentials {
+		return nil
+	}
+
+	c := w.get(workspace)
+	w.mu.Lock()
+	validated := c.validated
+	w.mu.Unlock()
+	if validated {
+		return nil
+	}
+
+	diags := validateCredentials(ctx, c.client, workspace)
+	if !diags.HasError() {
+		w.mu.Lock()
+		c.validated = true
+		w.mu.Unlock()
+	}
+
+	return diags
+}
+
+// resourceMeta returns the metadata to manage a resource with, targeting its workspace when it overrides the one of the
+// provider
+func resourceMeta(m interface{}, r *schema.ResourceData) ProviderMetadata {
+	meta := m.(ProviderMetadata)
+
+	// Data sources of the provider workspace, e.g. segment_event_library, have no workspace attribute
+	workspace, _ := r.Get(keyWorkspace).(string)
+	if workspace == "" || workspace == meta.Workspace || meta.Workspaces == nil {
+		return meta
+	}
+
+	c := meta.Workspaces.get(workspace)
+	meta.Workspace = workspace
+	meta.Client = c.client
+	meta.TrackingPlansConnections = c.connections
+
+	return meta
+}
+
+// importWithWorkspace imports resources whose ID is made of idParts parts separated by slashes, optionally prefixed by
+// the workspace of the resource. The workspace is only stored when it isn't the one of the provider, as resources of
+// the provider workspace leave it out of their config.
+// Example:
+// 	terraform import segment_source.example my-workspace/my-source
+func importWithWorkspace(idParts int) schema.StateContextFunc {
+	return func(_ context.Context, r *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+		parts := strings.Split(r.Id(), "/")
+		switch len(parts) {
+		case idParts:
+		case idParts + 1:
+			if parts[0] != m.(ProviderMetadata).Workspace {
+				if err := r.Set(keyWorkspace, parts[0]); err != nil {
+					return nil, err
+				}
+			}
+			r.SetId(strings.Join(parts[1:], "/"))
+		default:
+			return nil, fmt.Errorf("invalid import ID %q: expected %d slash separated parts, optionally prefixed by the workspace", r.Id(), idParts)
+		}
+
+		return []*schema.ResourceData{r}, nil
+	}
+}
+
+// importWithDefaults sets attributes of imported resources to their default, as they have no value in the state
+// otherwise, which would show as a change in the next plan
+func importWithDefaults(importState schema.StateContextFunc, defaults map[string]interface{}) schema.StateContextFunc {
+	return func(ctx context.Context, r *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+		for key, value := range defaults {
+			if err := r.Set(key, value); err != nil {
+				return nil, err
+			}
+		}
+
+		return importState(ctx, r, m)
+	}
+}
@@ -0,0 +1,156 @@
+package provider_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uswitch/terraform-provider-segment/internal/fake"
+	"github.com/uswitch/terraform-provider-segment/internal/provider"
+)
+
+// multiWorkspaceMeta returns metadata defaulting to the first workspace, and creating clients for the others
+func multiWorkspaceMeta(t *testing.T, workspaces ...*fake.Workspace) (provider.ProviderMetadata, map[string]int) {
+	created := map[string]int{}
+	meta := unitTestMeta(workspaces[0])
+	meta.Workspaces = provider.NewWorkspaceClients(func(slug string) provider.SegmentAPI {
+		created[slug]++
+		for _, ws := range workspaces {
+			if ws.Slug() == slug {
+				return ws
+			}
+		}
+		t.Fatalf("unexpected workspace %s", slug)
+		return nil
+	}, unitTestConcurrency)
+
+	return meta, created
+}
+
+func TestWorkspaceOverride(t *testing.T) {
+	ctx := context.Background()
+	dev := fake.NewWorkspace("dev")
+	prod := fake.NewWorkspace("prod")
+	meta, created := multiWorkspaceMeta(t, dev, prod)
+
+	source := newUnitTestResourceWithMeta(t, "segment_source", meta)
+	require.Empty(t, source.apply(map[string]interface{}{
+		"workspace":    "prod",
+		"source_name":  "my-source",
+		"catalog_name": "catalog/sources/javascript",
+	}))
+	destination := newUnitTestResourceWithMeta(t, "segment_destination", meta)
+	require.Empty(t, destination.apply(map[string]interface{}{
+		"workspace": "prod",
+		"source":    "my-source",
+		"name":      "google-analytics",
+		"enabled":   true,
+		"config": map[string]interface{}{
+			"trackingId": `{"type":"string","value":"UA-123"}`,
+		},
+	}))
+
+	_, err := prod.GetSource(ctx, "my-source")
+	assert.NoError(t, err)
+	_, err = dev.GetSource(ctx, "my-source")
+	assert.Error(t, err, "the source should not be created in the default workspace")
+	dest, err := prod.GetDestination(ctx, "my-source", "google-analytics")
+	require.NoError(t, err)
+	assert.Equal(t, "workspaces/prod/sources/my-source/destinations/google-analytics/config/trackingId", dest.Configs[0].Name)
+	assert.Equal(t, 1, created["prod"], "the client of a workspace should be shared by its resources")
+
+	require.Empty(t, destination.destroy())
+	require.Empty(t, source.destroy())
+	_, err = prod.GetSource(ctx, "my-source")
+	assert.Error(t, err)
+}
+
+func TestWorkspaceOverride_defaultWorkspace(t *testing.T) {
+	dev := fake.NewWorkspace("dev")
+	meta, created := multiWorkspaceMeta(t, dev)
+
+	r := newUnitTestResourceWithMeta(t, "segment_source", meta)
+	config := map[string]interface{}{
+		"source_name":  "my-source",
+		"catalog_name": "catalog/sources/javascript",
+	}
+	require.Empty(t, r.apply(config))
+
+	assert.Empty(t, r.attr("workspace"), "the provider workspace should not be stored")
+	assert.Nil(t, r.plan(config))
+	assert.Empty(t, created, "the provider client should be used")
+}
+
+func TestWorkspaceOverride_import(t *testing.T) {
+	ctx := context.Background()
+	dev := fake.NewWorkspace("dev")
+	prod := fake.NewWorkspace("prod")
+	meta, _ := multiWorkspaceMeta(t, dev, prod)
+	_, err := prod.CreateSource(ctx, "my-source", "catalog/sources/javascript")
+	require.NoError(t, err)
+	_, err = prod.CreateDestination(ctx, "my-source", "google-analytics", "CLOUD", true, nil)
+	require.NoError(t, err)
+
+	source := newUnitTestResourceWithMeta(t, "segment_source", meta)
+	require.Empty(t, source.importState("prod/my-source"))
+	assert.Equal(t, "my-source", source.id())
+	assert.Equal(t, "prod", source.attr("workspace"))
+
+	destination := newUnitTestResourceWithMeta(t, "segment_destination", meta)
+	require.Empty(t, destination.importState("prod/my-source/google-analytics"))
+	assert.Equal(t, "my-source/google-analytics", destination.id())
+	assert.Equal(t, "prod", destination.attr("workspace"))
+
+	unprefixed := newUnitTestResourceWithMeta(t, "segment_source", meta)
+	require.Empty(t, unprefixed.importState("my-source"))
+	assert.Empty(t, unprefixed.id(), "the source should be looked up in the default workspace")
+}
+
+func TestWorkspaceOverride_followsProviderWorkspace(t *testing.T) {
+	ctx := context.Background()
+	dev := fake.NewWorkspace("dev")
+	prod := fake.NewWorkspace("prod")
+	_, err := prod.CreateSource(ctx, "my-source", "catalog/sources/javascript")
+	require.NoError(t, err)
+	config := map[string]interface{}{
+		"source_name":  "my-source",
+		"catalog_name": "catalog/sources/javascript",
+	}
+
+	r := newUnitTestResource(t, "segment_source", dev)
+	require.Empty(t, r.apply(config))
+	r.meta = unitTestMeta(prod)
+
+	assert.Nil(t, r.plan(config), "the workspace of the provider should not show as a change")
+	require.Empty(t, r.refresh())
+	assert.Equal(t, "my-source", r.id(), "the source should be read from the new workspace of the provider")
+	assert.Empty(t, r.attr("workspace"))
+}
+
+func TestWorkspaceOverride_importFromProviderWorkspace(t *testing.T) {
+	ctx := context.Background()
+	dev := fake.NewWorkspace("dev")
+	_, err := dev.CreateSource(ctx, "my-source", "catalog/sources/javascript")
+	require.NoError(t, err)
+
+	r := newUnitTestResource(t, "segment_source", dev)
+	require.Empty(t, r.importState("dev/my-source"))
+
+	assert.Equal(t, "my-source", r.id())
+	assert.Empty(t, r.attr("workspace"), "the provider workspace should not be stored")
+	assert.Nil(t, r.plan(map[string]interface{}{
+		"source_name":  "my-source",
+		"catalog_name": "catalog/sources/javascript",
+	}))
+}
+
+func TestWorkspaceOverride_dataSourcesSchema(t *testing.T) {
+	for name, ds := range provider.New().DataSourcesMap {
+		if s, ok := ds.Schema["workspace"]; ok {
+			assert.True(t, s.Optional, name)
+			assert.False(t, s.Computed, name)
+			assert.False(t, s.ForceNew, name)
+		}
+	}
+}
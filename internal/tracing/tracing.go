@@ -0,0 +1,107 @@
+// Package tracing exports OpenTelemetry traces of the provider to an OTLP collector
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const serviceName = "terraform-provider-segment"
+
+// Tracer returns the tracer of a package of the provider, e.g. `configapi`. Spans are dropped unless Setup enabled
+// tracing.
+func Tracer(pkg string) trace.Tracer {
+	return otel.Tracer("github.com/uswitch/terraform-provider-segment/internal/" + pkg)
+}
+
+// Setup exports traces to the OTLP collector at OTEL_EXPORTER_OTLP_ENDPOINT, or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT, if
+// any. The other OTEL_EXPORTER_OTLP_* variables, like the headers and the timeout, are read by the exporter, and spans
+// are exported in batches, see the OTEL_BSP_* variables. The returned function exports the remaining spans and must be
+// called before exiting.
+func Setup(ctx context.Context) (func(context.Context) error, error) {
+	if !isEnabled() {
+		return func(context.Context) error { return nil }, nil
+	}
+	client, err := newClient(otlpEnv("PROTOCOL"))
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceNameKey.String(serviceName)),
+		resource.WithTelemetrySDK(),
+		// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES take precedence
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the OpenTelemetry resource: %w", err)
+	}
+
+	// The gRPC client connects in the background, an unreachable collector only fails the exports
+	exporter, err := otlptrace.New(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the OTLP exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// newClient returns the OTLP client of a protocol, http/protobuf being the default of the specification
+func newClient(protocol string) (otlptrace.Client, error) {
+	switch protocol {
+	case "", "http/protobuf":
+		return otlptracehttp.NewClient(), nil
+	case "grpc":
+		return otlptracegrpc.NewClient(), nil
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol %q, expected grpc or http/protobuf", protocol)
+	}
+}
+
+// EndSpan ends a span, marking it as failed if err isn't nil
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// isEnabled returns whether a collector is configured, and tracing isn't turned off by the environment variables of the
+// OpenTelemetry SDK
+func isEnabled() bool {
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return false
+	}
+
+	return !strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true") && os.Getenv("OTEL_TRACES_EXPORTER") != "none"
+}
+
+// otlpEnv returns the value of an OTLP exporter variable, the one specific to traces taking precedence
+// Example:
+// 	otlpEnv("PROTOCOL") // OTEL_EXPORTER_OTLP_TRACES_PROTOCOL, or else OTEL_EXPORTER_OTLP_PROTOCOL
+func otlpEnv(name string) string {
+	if value := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_" + name); value != "" {
+		return value
+	}
+
+	return os.Getenv("OTEL_EXPORTER_OTLP_" + name)
+}
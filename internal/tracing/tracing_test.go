@@ -0,0 +1,218 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uswitch/terraform-provider-segment/internal/tracing"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// collector records the OTLP/HTTP requests it receives
+type collector struct {
+	*httptest.Server
+	requests []*http.Request
+	bodies   []*coltracepb.ExportTraceServiceRequest
+}
+
+func newCollector(t *testing.T) *collector {
+	c := &collector{}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		body := &coltracepb.ExportTraceServiceRequest{}
+		assert.NoError(t, proto.Unmarshal(raw, body))
+		c.requests = append(c.requests, r)
+		c.bodies = append(c.bodies, body)
+		w.Header().Set("Content-Type", "application/x-protobuf")
+	}))
+	t.Cleanup(c.Close)
+
+	return c
+}
+
+// grpcCollector records the OTLP/gRPC requests it receives
+type grpcCollector struct {
+	coltracepb.UnimplementedTraceServiceServer
+	mu     sync.Mutex
+	bodies []*coltracepb.ExportTraceServiceRequest
+}
+
+func (c *grpcCollector) Export(_ context.Context, req *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.bodies = append(c.bodies, req)
+
+	return &coltracepb.ExportTraceServiceResponse{}, nil
+}
+
+func newGRPCCollector(t *testing.T) (*grpcCollector, string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	c := &grpcCollector{}
+	server := grpc.NewServer()
+	coltracepb.RegisterTraceServiceServer(server, c)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	return c, listener.Addr().String()
+}
+
+// exportedSpans returns the spans of the first scope of the first resource of a request
+func exportedSpans(t *testing.T, body *coltracepb.ExportTraceServiceRequest) []*tracepb.Span {
+	require.Len(t, body.ResourceSpans, 1)
+	require.Len(t, body.ResourceSpans[0].ScopeSpans, 1)
+
+	return body.ResourceSpans[0].ScopeSpans[0].Spans
+}
+
+func TestSetup_disabledWithoutEndpoint(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+
+	shutdown, err := tracing.Setup(context.Background())
+	require.NoError(t, err)
+
+	_, span := tracing.Tracer("test").Start(context.Background(), "span")
+	assert.False(t, span.SpanContext().IsValid(), "spans should be dropped")
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestSetup_exportsToEndpoint(t *testing.T) {
+	c := newCollector(t)
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", c.URL+"/")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "api-key=secret%3D,tenant=platform")
+	t.Setenv("OTEL_SERVICE_NAME", "")
+
+	shutdown, err := tracing.Setup(context.Background())
+	require.NoError(t, err)
+	defer shutdown(context.Background())
+
+	ctx, parent := tracing.Tracer("provider").Start(context.Background(), "create segment_source")
+	_, child := tracing.Tracer("provider").Start(ctx, "Config API POST")
+	tracing.EndSpan(child, errors.New("Bad Gateway"))
+	tracing.EndSpan(parent, nil)
+	require.NoError(t, shutdown(context.Background()))
+
+	require.Len(t, c.requests, 1)
+	req := c.requests[0]
+	assert.Equal(t, "/v1/traces", req.URL.Path)
+	assert.Equal(t, "application/x-protobuf", req.Header.Get("Content-Type"))
+	assert.Equal(t, "secret=", req.Header.Get("api-key"))
+	assert.Equal(t, "platform", req.Header.Get("tenant"))
+
+	var serviceName string
+	for _, attr := range c.bodies[0].ResourceSpans[0].Resource.Attributes {
+		if attr.Key == "service.name" {
+			serviceName = attr.Value.GetStringValue()
+		}
+	}
+	assert.Equal(t, "terraform-provider-segment", serviceName)
+
+	spans := exportedSpans(t, c.bodies[0])
+	require.Len(t, spans, 2)
+	request, operation := spans[0], spans[1]
+	assert.Equal(t, "Config API POST", request.Name)
+	assert.Equal(t, operation.TraceId, request.TraceId)
+	assert.Equal(t, operation.SpanId, request.ParentSpanId)
+	assert.Empty(t, operation.ParentSpanId)
+	assert.Equal(t, tracepb.Status_STATUS_CODE_ERROR, request.Status.Code)
+	assert.Equal(t, "Bad Gateway", request.Status.Message)
+	assert.Equal(t, "exception", request.Events[0].Name)
+	assert.Equal(t, tracepb.Status_STATUS_CODE_UNSET, operation.Status.Code)
+}
+
+func TestSetup_exportsOverGRPC(t *testing.T) {
+	c, addr := newGRPCCollector(t)
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://"+addr)
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "grpc")
+
+	shutdown, err := tracing.Setup(context.Background())
+	require.NoError(t, err)
+	defer shutdown(context.Background())
+
+	_, span := tracing.Tracer("test").Start(context.Background(), "span")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	require.Len(t, c.bodies, 1)
+	spans := exportedSpans(t, c.bodies[0])
+	require.Len(t, spans, 1)
+	assert.Equal(t, "span", spans[0].Name)
+}
+
+func TestSetup_tracesEndpointTakesPrecedence(t *testing.T) {
+	c := newCollector(t)
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://unused:4318")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", c.URL+"/custom")
+
+	shutdown, err := tracing.Setup(context.Background())
+	require.NoError(t, err)
+	defer shutdown(context.Background())
+
+	_, span := tracing.Tracer("test").Start(context.Background(), "span")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+
+	require.Len(t, c.requests, 1)
+	assert.Equal(t, "/custom", c.requests[0].URL.Path)
+}
+
+func TestSetup_disabledByEnvironment(t *testing.T) {
+	for name, env := range map[string]string{"OTEL_SDK_DISABLED": "true", "OTEL_TRACES_EXPORTER": "none"} {
+		t.Run(name, func(t *testing.T) {
+			c := newCollector(t)
+			t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", c.URL)
+			t.Setenv(name, env)
+
+			shutdown, err := tracing.Setup(context.Background())
+			require.NoError(t, err)
+
+			_, span := tracing.Tracer("test").Start(context.Background(), "span")
+			span.End()
+			require.NoError(t, shutdown(context.Background()))
+			assert.Empty(t, c.requests)
+		})
+	}
+}
+
+func TestSetup_unsupportedProtocol(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318")
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "grpc")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "http/json")
+
+	_, err := tracing.Setup(context.Background())
+	assert.EqualError(t, err, `unsupported OTLP protocol "http/json", expected grpc or http/protobuf`)
+}
+
+func TestSetup_timeout(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Second)
+	}))
+	defer slow.Close()
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", slow.URL)
+	t.Setenv("OTEL_EXPORTER_OTLP_TIMEOUT", "50")
+
+	shutdown, err := tracing.Setup(context.Background())
+	require.NoError(t, err)
+	_, span := tracing.Tracer("test").Start(context.Background(), "span")
+	span.End()
+
+	start := time.Now()
+	_ = shutdown(context.Background())
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "the export should time out")
+}
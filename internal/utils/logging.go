@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-log/tfsdklog"
+)
+
+// Logging subsystems of the provider. The level of each one can be set independently of the rest of the provider with
+// the TF_LOG_PROVIDER_<SUBSYSTEM> environment variable.
+// Example:
+// 	TF_LOG_PROVIDER_SEGMENT_API=DEBUG terraform plan
+const (
+	// LogSegmentAPI logs the calls to the Config API: retries, token refreshes and HTTP traces
+	LogSegmentAPI = "segment_api"
+	// LogTrackingPlanMerge logs the comparisons between the rules of tracking plans and their configuration
+	LogTrackingPlanMerge = "tracking_plan_merge"
+	// LogConnectionCache logs the loading of the tracking plans connections cache
+	LogConnectionCache = "connection_cache"
+)
+
+var logSubsystems = []string{LogSegmentAPI, LogTrackingPlanMerge, LogConnectionCache}
+
+// WithLogging returns a context logging with the provider logger and its subsystems, with fields added to every entry.
+// The provider logger writes to stderr when the context doesn't already have one.
+func WithLogging(ctx context.Context, fields map[string]interface{}) context.Context {
+	// Creating a subsystem is a no-op without a provider logger
+	if tflog.NewSubsystem(ctx, LogSegmentAPI) == ctx {
+		ctx = tfsdklog.NewRootProviderLogger(ctx,
+			tfsdklog.WithLogName("segment"),
+			tfsdklog.WithLevelFromEnv("TF_LOG_PROVIDER"),
+			tfsdklog.WithStderrFromInit(),
+		)
+	}
+
+	for k, v := range fields {
+		ctx = tflog.SetField(ctx, k, v)
+	}
+
+	for _, subsystem := range logSubsystems {
+		ctx = tflog.NewSubsystem(ctx, subsystem, tflog.WithLevelFromEnv("TF_LOG_PROVIDER", subsystem), tflog.WithRootFields())
+	}
+
+	return ctx
+}
+
+// IsDebugOrHigher returns whether TF_LOG_PROVIDER_<SUBSYSTEM> enables the debug logs of a subsystem, e.g. to skip
+// building expensive log entries otherwise
+func IsDebugOrHigher(subsystem string) bool {
+	level := hclog.LevelFromString(os.Getenv("TF_LOG_PROVIDER_" + strings.ToUpper(subsystem)))
+	return level != hclog.NoLevel && level <= hclog.Debug
+}
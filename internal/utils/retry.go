@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// RetryPolicy configures how Retry retries failing calls
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt. 0 disables retries.
+	MaxRetries int
+	// InitialDelay is the upper bound of the delay before the first retry. It doubles after every retry.
+	InitialDelay time.Duration
+	// MaxDelay caps the delay between two retries, including delays requested by the server. 0 means no cap.
+	MaxDelay time.Duration
+}
+
+// RetryableError marks an error as transient, e.g. a 429, a 5xx or a network error
+type RetryableError struct {
+	Err error
+	// RetryAfter is the delay requested by the server before retrying, if any
+	RetryAfter time.Duration
+}
+
+func (e *RetryableError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryableError) Unwrap() error {
+	return e.Err
+}
+
+// Retry calls the passed function until it succeeds, fails with an error which isn't a *RetryableError, or the retries of
+// the policy are exhausted. Delays grow exponentially with full jitter, unless the error specifies a RetryAfter delay.
+// Both are capped by MaxDelay. It gives up without waiting when the context would expire before the next attempt, and
+// stops waiting as soon as the context is done. The returned error is never a *RetryableError.
+func Retry(ctx context.Context, policy RetryPolicy, call func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := call()
+
+		var retryable *RetryableError
+		if err == nil || !errors.As(err, &retryable) {
+			return err
+		}
+		if attempt >= policy.MaxRetries {
+			return retryable.Err
+		}
+
+		delay := policy.delay(attempt)
+		if retryable.RetryAfter > 0 {
+			delay = policy.capDelay(retryable.RetryAfter)
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return fmt.Errorf("%w before retrying after %s: %s", context.DeadlineExceeded, delay, retryable.Err)
+		}
+		tflog.SubsystemInfo(ctx, LogSegmentAPI, "Retrying a failed call", map[string]interface{}{
+			"error":      retryable.Err.Error(),
+			"delay":      delay.String(),
+			"tries_left": policy.MaxRetries - attempt,
+		})
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w while retrying after: %s", ctx.Err(), retryable.Err)
+		case <-timer.C:
+		}
+	}
+}
+
+// delay returns a random delay up to InitialDelay * 2^attempt, capped by MaxDelay
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	backoff := p.InitialDelay
+	for i := 0; i < attempt && backoff < math.MaxInt64/2 && (p.MaxDelay <= 0 || backoff < p.MaxDelay); i++ {
+		backoff *= 2
+	}
+	backoff = p.capDelay(backoff)
+	if backoff <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(backoff) + 1))
+}
+
+// capDelay caps a delay by MaxDelay
+func (p RetryPolicy) capDelay(delay time.Duration) time.Duration {
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+
+	return delay
+}
@@ -0,0 +1,113 @@
+package utils_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uswitch/terraform-provider-segment/internal/utils"
+)
+
+var errTransient = errors.New("transient")
+
+func TestRetry_RetriesTransientErrors(t *testing.T) {
+	calls := 0
+	err := utils.Retry(context.Background(), utils.RetryPolicy{MaxRetries: 3, InitialDelay: time.Millisecond}, func() error {
+		calls++
+		if calls < 3 {
+			return &utils.RetryableError{Err: errTransient}
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetry_GivesUp(t *testing.T) {
+	calls := 0
+	err := utils.Retry(context.Background(), utils.RetryPolicy{MaxRetries: 2, InitialDelay: time.Millisecond}, func() error {
+		calls++
+		return &utils.RetryableError{Err: errTransient}
+	})
+
+	assert.Equal(t, errTransient, err, "the last error should be returned unwrapped")
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetry_DoesNotRetryPermanentErrors(t *testing.T) {
+	permanent := errors.New("permanent")
+	calls := 0
+	err := utils.Retry(context.Background(), utils.RetryPolicy{MaxRetries: 2, InitialDelay: time.Millisecond}, func() error {
+		calls++
+		return permanent
+	})
+
+	assert.Equal(t, permanent, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetry_HonoursRetryAfter(t *testing.T) {
+	calls := 0
+	start := time.Now()
+	err := utils.Retry(context.Background(), utils.RetryPolicy{MaxRetries: 1, InitialDelay: time.Hour}, func() error {
+		calls++
+		if calls == 1 {
+			return &utils.RetryableError{Err: errTransient, RetryAfter: 20 * time.Millisecond}
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestRetry_StopsWhenContextIsDone(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := utils.Retry(ctx, utils.RetryPolicy{MaxRetries: 5, InitialDelay: time.Hour, MaxDelay: time.Hour}, func() error {
+		return &utils.RetryableError{Err: errTransient, RetryAfter: time.Hour}
+	})
+
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Contains(t, err.Error(), errTransient.Error())
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestRetry_CapsRetryAfter(t *testing.T) {
+	calls := 0
+	start := time.Now()
+	err := utils.Retry(context.Background(), utils.RetryPolicy{MaxRetries: 1, MaxDelay: 10 * time.Millisecond}, func() error {
+		calls++
+		if calls == 1 {
+			return &utils.RetryableError{Err: errTransient, RetryAfter: time.Hour}
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Less(t, time.Since(start), time.Second, "Retry-After should be capped by MaxDelay")
+}
+
+func TestRetry_GivesUpWhenTheDelayExceedsTheDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	calls := 0
+	start := time.Now()
+	err := utils.Retry(ctx, utils.RetryPolicy{MaxRetries: 5}, func() error {
+		calls++
+		return &utils.RetryableError{Err: errTransient, RetryAfter: time.Hour}
+	})
+
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Contains(t, err.Error(), errTransient.Error())
+	assert.Equal(t, 1, calls)
+	assert.Less(t, time.Since(start), time.Second, "Retry should not wait for a retry it can't make")
+}
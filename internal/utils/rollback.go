@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// rollbackTimeout bounds the undoing of the steps of a failed operation
+const rollbackTimeout = time.Minute
+
+// Rollback records how to undo the steps of a multi-step operation, e.g. a create, so that a failure doesn't leave
+// behind Segment resources Terraform doesn't know about
+// Example:
+// 	var rollback utils.Rollback
+// 	rollback.Add("Source "+srcName, func(ctx context.Context) error { return client.DeleteSource(ctx, srcName) })
+// 	if d := updateTrackingPlan(ctx, r, meta); d != nil {
+// 		return rollback.OnError(ctx, r, *d)
+// 	}
+type Rollback struct {
+	steps []rollbackStep
+}
+
+type rollbackStep struct {
+	resource string
+	undo     func(ctx context.Context) error
+}
+
+// Add records how to undo a completed step, described by the resource it created or changed, e.g. "Source foo"
+func (r *Rollback) Add(resource string, undo func(ctx context.Context) error) {
+	r.steps = append(r.steps, rollbackStep{resource: resource, undo: undo})
+}
+
+// OnError undoes the completed steps, in reverse order, if diags has errors. The resource is then removed from the
+// state as it no longer exists. Resources which couldn't be cleaned up are reported by a warning appended to diags.
+// Steps are undone even if the operation timed out.
+func (r *Rollback) OnError(ctx context.Context, d *schema.ResourceData, diags diag.Diagnostics) diag.Diagnostics {
+	if !diags.HasError() {
+		return diags
+	}
+	d.SetId("")
+
+	ctx, cancel := context.WithTimeout(detachedContext{ctx}, rollbackTimeout)
+	defer cancel()
+
+	var lingering []string
+	for i := len(r.steps) - 1; i >= 0; i-- {
+		step := r.steps[i]
+		tflog.Info(ctx, "Rolling back", map[string]interface{}{"resource": step.resource})
+		if err := step.undo(ctx); err != nil {
+			tflog.Warn(ctx, "Failed to roll back", map[string]interface{}{"resource": step.resource, "error": err.Error()})
+			lingering = append(lingering, fmt.Sprintf("%s could not be cleaned up because of %s.", step.resource, err))
+		}
+	}
+	r.steps = nil
+
+	if len(lingering) > 0 {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Lingering Segment resources",
+			Detail:   strings.Join(lingering, "\n") + "\nCheck Segment for manual cleanup",
+		})
+	}
+
+	return diags
+}
+
+// detachedContext keeps the values of a context, e.g. its logger, without its cancellation
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
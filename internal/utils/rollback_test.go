@@ -0,0 +1,87 @@
+package utils_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uswitch/terraform-provider-segment/internal/utils"
+)
+
+func newRollbackTestData(t *testing.T) *schema.ResourceData {
+	d := schema.TestResourceDataRaw(t, map[string]*schema.Schema{}, map[string]interface{}{})
+	d.SetId("created")
+
+	return d
+}
+
+func TestRollback_doesNothingOnSuccess(t *testing.T) {
+	d := newRollbackTestData(t)
+	undone := false
+	var rollback utils.Rollback
+	rollback.Add("Source foo", func(context.Context) error {
+		undone = true
+		return nil
+	})
+
+	warning := diag.Diagnostics{{Severity: diag.Warning, Summary: "warning"}}
+	assert.Equal(t, warning, rollback.OnError(context.Background(), d, warning))
+	assert.False(t, undone)
+	assert.Equal(t, "created", d.Id())
+}
+
+func TestRollback_undoesStepsInReverseOrder(t *testing.T) {
+	d := newRollbackTestData(t)
+	var undone []string
+	var rollback utils.Rollback
+	for _, resource := range []string{"Source foo", "Destination foo/bar"} {
+		resource := resource
+		rollback.Add(resource, func(context.Context) error {
+			undone = append(undone, resource)
+			return nil
+		})
+	}
+
+	failure := diag.Errorf("failed")
+	assert.Equal(t, failure, rollback.OnError(context.Background(), d, failure))
+	assert.Equal(t, []string{"Destination foo/bar", "Source foo"}, undone)
+	assert.Empty(t, d.Id(), "the resource should be removed from the state")
+}
+
+func TestRollback_reportsLingeringResources(t *testing.T) {
+	d := newRollbackTestData(t)
+	undone := false
+	var rollback utils.Rollback
+	rollback.Add("Source foo", func(context.Context) error {
+		undone = true
+		return nil
+	})
+	rollback.Add("Destination foo/bar", func(context.Context) error { return errors.New("HTTP 502") })
+	rollback.Add("Destination filter foo/bar/df_1", func(context.Context) error { return errors.New("HTTP 503") })
+
+	diags := rollback.OnError(context.Background(), d, diag.Errorf("failed"))
+
+	assert.True(t, undone, "the other steps should still be undone")
+	require.Len(t, diags, 2)
+	assert.Equal(t, diag.Warning, diags[1].Severity)
+	assert.Equal(t, "Lingering Segment resources", diags[1].Summary)
+	assert.Equal(t, "Destination filter foo/bar/df_1 could not be cleaned up because of HTTP 503.\n"+
+		"Destination foo/bar could not be cleaned up because of HTTP 502.\n"+
+		"Check Segment for manual cleanup", diags[1].Detail)
+}
+
+func TestRollback_undoesStepsOfCancelledOperations(t *testing.T) {
+	d := newRollbackTestData(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var rollback utils.Rollback
+	rollback.Add("Source foo", func(ctx context.Context) error { return ctx.Err() })
+
+	diags := rollback.OnError(ctx, d, diag.FromErr(context.Canceled))
+	assert.Len(t, diags, 1, "the step should be undone despite the cancellation")
+}
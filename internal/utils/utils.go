@@ -2,11 +2,11 @@ package utils
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"reflect"
 	"strings"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -18,6 +18,12 @@ func DiagFromErrPtr(err error) *diag.Diagnostics {
 	return &d
 }
 
+// IsNotFound returns whether an error is a Segment API error for a resource which doesn't exist
+func IsNotFound(err error) bool {
+	var apiErr *segment.SegmentApiError
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound
+}
+
 // Converts a segment resource path to its id
 // E.g: workspaces/myworkspace/sources/mysource => mysource
 func PathToName(path string) string {
@@ -29,20 +35,6 @@ func PathToName(path string) string {
 	return path
 }
 
-// withBackoff calls the passed function returning a result and an error and performs an exponential backoff if it fails with a 429 HTTP status code
-func WithBackoff(call func() (interface{}, error), initialRetryDelay time.Duration, maxRetries int) (interface{}, error) {
-	results, err := call()
-	if err != nil {
-		if e, ok := err.(*segment.SegmentApiError); ok && e.Code == http.StatusTooManyRequests && maxRetries > 0 {
-			log.Printf("[INFO] Backoff: failed, waiting %sms before retrying, %d tries left", initialRetryDelay, maxRetries)
-			time.Sleep(initialRetryDelay)
-			return WithBackoff(call, initialRetryDelay*2, maxRetries-1)
-		}
-	}
-
-	return results, err
-}
-
 func unmarshalGeneric(input string) interface{} {
 	var decodedStr interface{}
 	if err := json.Unmarshal([]byte(input), &decodedStr); err != nil {
@@ -1,9 +1,14 @@
 package main
 
 import (
+	"context"
+	"log"
+	"time"
+
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
 	"github.com/uswitch/terraform-provider-segment/internal/provider"
+	"github.com/uswitch/terraform-provider-segment/internal/tracing"
 )
 
 // Format example tf files
@@ -12,7 +17,26 @@ import (
 // Run the docs generation tool
 //go:generate go run github.com/hashicorp/terraform-plugin-docs/cmd/tfplugindocs
 
+// tracingShutdownTimeout bounds the export of the remaining spans when the provider exits
+const tracingShutdownTimeout = 2 * time.Second
+
 func main() {
+	ctx := context.Background()
+
+	shutdownTracing, err := tracing.Setup(ctx)
+	if err != nil {
+		log.Printf("[WARN] OpenTelemetry tracing is disabled: %s", err)
+	} else {
+		// Export the remaining spans once Terraform stops the provider, which it kills if it doesn't exit shortly after
+		defer func() {
+			ctx, cancel := context.WithTimeout(ctx, tracingShutdownTimeout)
+			defer cancel()
+			if err := shutdownTracing(ctx); err != nil {
+				log.Printf("[WARN] Failed to export the remaining OpenTelemetry spans: %s", err)
+			}
+		}()
+	}
+
 	plugin.Serve(&plugin.ServeOpts{
 		ProviderFunc: func() *schema.Provider {
 			return provider.New()